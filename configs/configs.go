@@ -1,6 +1,8 @@
 package configs
 
 import (
+	"errors"
+	"io/fs"
 	"log"
 	"os"
 
@@ -16,6 +18,20 @@ type Config struct {
 	REDIS_ADDRESS  string
 	REDIS_USERNAME string
 	REDIS_PASSWORD string
+
+	SHORT_CODE_STRATEGY string
+	SHORT_CODE_LENGTH   string
+	SHORT_CODE_SALT     string
+
+	CHECK_REDIRECT_LOOPS string
+
+	NOT_YET_AVAILABLE_URL string
+
+	TRASH_RETENTION_DAYS string
+
+	GEOIP_DB_PATH string
+
+	QR_LOGO_PATH string
 }
 
 var Env *Config
@@ -26,7 +42,10 @@ func init() {
 
 	if os.Getenv("MODE") != "production" {
 		err := godotenv.Load()
-		if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			// Tests run from each package's directory, where there is no .env
+			log.Println("No .env file found, using the environment")
+		} else if err != nil {
 			log.Fatalf("Error loading .env file: %v", err)
 		}
 
@@ -40,4 +59,18 @@ func init() {
 	Env.REDIS_ADDRESS = os.Getenv("REDIS_ADDRESS")
 	Env.REDIS_USERNAME = os.Getenv("REDIS_USERNAME")
 	Env.REDIS_PASSWORD = os.Getenv("REDIS_PASSWORD")
+
+	Env.SHORT_CODE_STRATEGY = os.Getenv("SHORT_CODE_STRATEGY")
+	Env.SHORT_CODE_LENGTH = os.Getenv("SHORT_CODE_LENGTH")
+	Env.SHORT_CODE_SALT = os.Getenv("SHORT_CODE_SALT")
+
+	Env.CHECK_REDIRECT_LOOPS = os.Getenv("CHECK_REDIRECT_LOOPS")
+
+	Env.NOT_YET_AVAILABLE_URL = os.Getenv("NOT_YET_AVAILABLE_URL")
+
+	Env.TRASH_RETENTION_DAYS = os.Getenv("TRASH_RETENTION_DAYS")
+
+	Env.GEOIP_DB_PATH = os.Getenv("GEOIP_DB_PATH")
+
+	Env.QR_LOGO_PATH = os.Getenv("QR_LOGO_PATH")
 }
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/manlikehenryy/url-shortener-go/helpers"
+	"github.com/manlikehenryy/url-shortener-go/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// accountSettings only holds the settings a user may change themselves,
+// fields left out of the request keep their current value
+type accountSettings struct {
+	DedupeUrls  *bool   `json:"dedupeUrls"`
+	FallbackUrl *string `json:"fallbackUrl"` // "" removes it
+}
+
+func GetAccount(c *gin.Context) {
+	userId, ok := c.MustGet("userId").(primitive.ObjectID)
+	if !ok {
+		helpers.SendError(c, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	user, err := findUser(userId)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			helpers.SendError(c, http.StatusNotFound, "Account not found")
+		} else {
+			helpers.SendError(c, http.StatusInternalServerError, "Failed to retrieve account")
+		}
+		return
+	}
+
+	helpers.SendJSON(c, http.StatusOK, gin.H{
+		"data": user,
+	})
+}
+
+func UpdateAccountSettings(c *gin.Context) {
+	userId, ok := c.MustGet("userId").(primitive.ObjectID)
+	if !ok {
+		helpers.SendError(c, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	var settings accountSettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		log.Println("Unable to parse body:", err)
+		helpers.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	set := bson.M{"updatedAt": time.Now()}
+	update := bson.M{"$set": set}
+	if settings.DedupeUrls != nil {
+		set["dedupeUrls"] = *settings.DedupeUrls
+	}
+
+	if settings.FallbackUrl != nil {
+		if *settings.FallbackUrl == "" {
+			update["$unset"] = bson.M{"fallbackUrl": ""}
+		} else {
+			fallbackUrl, err := checkDestination(*settings.FallbackUrl)
+			if err != nil {
+				helpers.SendValidationError(c, helpers.FieldErrors{"fallbackUrl": err.Error()})
+				return
+			}
+			set["fallbackUrl"] = fallbackUrl
+		}
+	}
+
+	result, err := usersCollection.UpdateOne(context.Background(), bson.M{"_id": userId}, update)
+	if err != nil {
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to update settings")
+		return
+	}
+
+	if result.MatchedCount == 0 {
+		helpers.SendError(c, http.StatusNotFound, "Account not found")
+		return
+	}
+
+	helpers.SendJSON(c, http.StatusOK, gin.H{
+		"message": "Settings updated successfully",
+	})
+}
+
+func findUser(userId primitive.ObjectID) (models.User, error) {
+	var user models.User
+	err := usersCollection.FindOne(context.Background(), bson.M{"_id": userId}).Decode(&user)
+	return user, err
+}
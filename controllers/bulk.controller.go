@@ -0,0 +1,421 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-redis/redis/v8"
+	"github.com/manlikehenryy/url-shortener-go/database"
+	"github.com/manlikehenryy/url-shortener-go/helpers"
+	"github.com/manlikehenryy/url-shortener-go/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const maxBulkUrls = 500
+
+type bulkUrlResult struct {
+	Index  int                 `json:"index"`
+	Status string              `json:"status"`
+	Data   *models.Url         `json:"data,omitempty"`
+	Error  string              `json:"error,omitempty"`
+	Errors helpers.FieldErrors `json:"errors,omitempty"`
+}
+
+// bulkItem tracks one url of a batch while it is being created, err is
+// set as soon as the item fails so later steps skip it
+type bulkItem struct {
+	url         models.Url
+	shortURL    string
+	err         string
+	fieldErrors helpers.FieldErrors
+}
+
+func (item *bulkItem) failValidation(fieldErrors helpers.FieldErrors) {
+	item.err = "Validation failed"
+	item.fieldErrors = fieldErrors
+}
+
+func BulkCreateUrl(c *gin.Context) {
+	userId, ok := c.MustGet("userId").(primitive.ObjectID)
+	if !ok {
+		helpers.SendError(c, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	items, err := readBulkUrls(c)
+	if err != nil {
+		log.Println("Unable to parse body:", err)
+		helpers.SendError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(items) == 0 {
+		helpers.SendError(c, http.StatusBadRequest, "No urls provided")
+		return
+	}
+
+	if len(items) > maxBulkUrls {
+		helpers.SendError(c, http.StatusBadRequest, fmt.Sprintf("A batch can contain at most %d urls", maxBulkUrls))
+		return
+	}
+
+	// The whole batch goes on one domain
+	domain, err := urlDomain(userId, c.Query("domain"))
+	if err != nil {
+		helpers.SendValidationError(c, helpers.FieldErrors{"domain": err.Error()})
+		return
+	}
+
+	for i := range items {
+		item := &items[i]
+		if item.err != "" {
+			continue
+		}
+
+		item.url.UserId = userId
+		if err := binding.Validator.ValidateStruct(&item.url); err != nil {
+			if fieldErrors, ok := helpers.BindingFieldErrors(err); ok {
+				item.failValidation(fieldErrors)
+			} else {
+				item.err = err.Error()
+			}
+			continue
+		}
+		if err := validateNewUrl(&item.url); err != nil {
+			item.failValidation(err.(helpers.FieldErrors))
+		}
+	}
+
+	storeBulkUrls(domain, items)
+
+	results := make([]bulkUrlResult, len(items))
+	created := 0
+	for i := range items {
+		item := &items[i]
+		results[i].Index = i
+		if item.err != "" {
+			results[i].Status = "failed"
+			results[i].Error = item.err
+			results[i].Errors = item.fieldErrors
+			continue
+		}
+
+		created++
+		item.url.ShortUrl = fullShortURL(domain, item.shortURL)
+		results[i].Status = "created"
+		results[i].Data = &item.url
+	}
+
+	status := http.StatusCreated
+	if created < len(items) {
+		status = http.StatusMultiStatus
+	}
+
+	helpers.SendJSON(c, status, gin.H{
+		"data":    results,
+		"message": fmt.Sprintf("%d of %d urls created", created, len(items)),
+		"meta": gin.H{
+			"total":   len(items),
+			"created": created,
+			"failed":  len(items) - created,
+		},
+	})
+}
+
+// readBulkUrls accepts either a JSON array of urls or a CSV upload with
+// originalUrl, expiration and alias columns
+func readBulkUrls(c *gin.Context) ([]bulkItem, error) {
+	contentType := c.ContentType()
+
+	if strings.HasPrefix(contentType, "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, errors.New("CSV file is required")
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, errors.New("Unable to read CSV file")
+		}
+		defer file.Close()
+
+		return bulkItemsFromCSV(file)
+	}
+
+	if contentType == "text/csv" {
+		return bulkItemsFromCSV(c.Request.Body)
+	}
+
+	var urls []models.Url
+	if err := json.NewDecoder(c.Request.Body).Decode(&urls); err != nil {
+		return nil, errors.New("Invalid request body")
+	}
+
+	items := make([]bulkItem, len(urls))
+	for i := range urls {
+		items[i].url = urls[i]
+	}
+	return items, nil
+}
+
+func bulkItemsFromCSV(r io.Reader) ([]bulkItem, error) {
+	records, err := helpers.ReadCSVRecords(r)
+	if err != nil {
+		return nil, errors.New("Invalid CSV file: " + err.Error())
+	}
+
+	items := make([]bulkItem, len(records))
+	for i, record := range records {
+		items[i].url = models.Url{
+			OriginalUrl: record["originalurl"],
+			Alias:       record["alias"],
+		}
+
+		if expiration := record["expiration"]; expiration != "" {
+			seconds, err := strconv.ParseInt(expiration, 10, 64)
+			if err != nil {
+				items[i].failValidation(helpers.FieldErrors{"expiration": "must be a number of seconds"})
+				continue
+			}
+			items[i].url.Expiration = seconds
+		}
+	}
+
+	return items, nil
+}
+
+// storeBulkUrls claims codes on a domain in Redis and inserts the documents
+// in Mongo with a single round trip each, recording failures on the items
+func storeBulkUrls(domain string, items []bulkItem) {
+	// Pick a code for every item, catching aliases repeated within the batch
+	aliases := make(map[string]bool)
+	var codes []string
+	for i := range items {
+		item := &items[i]
+		if item.err != "" {
+			continue
+		}
+
+		if item.url.Alias != "" {
+			if aliases[item.url.Alias] {
+				item.err = "Alias is used more than once in this batch"
+				continue
+			}
+			aliases[item.url.Alias] = true
+			item.shortURL = item.url.Alias
+		} else {
+			shortURL, err := shortCodeGenerator.Generate(item.url.OriginalUrl)
+			if err != nil {
+				log.Println(err)
+				item.err = "Failed to generate short url"
+				continue
+			}
+			item.shortURL = shortURL
+		}
+		codes = append(codes, item.shortURL)
+	}
+
+	taken, err := takenShortURLs(domain, codes)
+	if err != nil {
+		log.Println("Database error:", err)
+		failPendingBulkItems(items, "Failed to check short url")
+		return
+	}
+
+	// Claim every free code in one pipeline. A generated code that turns out
+	// to be taken is cleared so it gets retried on its own below.
+	pipe := database.RDB.Pipeline()
+	claims := make(map[int]*redis.BoolCmd)
+	for i := range items {
+		item := &items[i]
+		if item.err != "" {
+			continue
+		}
+
+		if taken[item.shortURL] {
+			if item.url.Alias != "" {
+				item.err = "Alias is already taken"
+			} else {
+				item.shortURL = ""
+			}
+			continue
+		}
+		claims[i] = pipe.SetNX(ctx, redisKey(domain, item.shortURL), item.url.OriginalUrl, redisTTL(&item.url))
+	}
+	if len(claims) > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			log.Println("Redis pipeline error:", err)
+		}
+	}
+
+	for i, claim := range claims {
+		item := &items[i]
+		if claim.Err() != nil {
+			item.err = "Failed to store URL"
+		} else if !claim.Val() {
+			if item.url.Alias != "" {
+				item.err = "Alias is already taken"
+			} else {
+				item.shortURL = ""
+			}
+		}
+	}
+
+	for i := range items {
+		item := &items[i]
+		if item.err != "" || item.shortURL != "" {
+			continue
+		}
+
+		shortURL, err := reserveShortURL(domain, "", item.url.OriginalUrl, redisTTL(&item.url))
+		if err != nil {
+			log.Println(err)
+			item.err = "Failed to store URL"
+			continue
+		}
+		item.shortURL = shortURL
+	}
+
+	var docs []interface{}
+	var inserted []int
+	aliased := make(map[int]bool)
+	for i := range items {
+		item := &items[i]
+		if item.err != "" {
+			continue
+		}
+
+		// prepareNewUrl clears the alias, a duplicate key is reported
+		// differently for the codes that were generated
+		aliased[len(inserted)] = item.url.Alias != ""
+		prepareNewUrl(&item.url, item.shortURL)
+		item.url.Domain = domain
+		item.url.ID = primitive.NewObjectID()
+		docs = append(docs, item.url)
+		inserted = append(inserted, i)
+	}
+
+	if len(docs) == 0 {
+		return
+	}
+
+	failed := make(map[int]string)
+	_, err = urlCollection.InsertMany(context.Background(), docs, options.InsertMany().SetOrdered(false))
+	if err != nil {
+		log.Println("Database error:", err)
+
+		var bulkErr mongo.BulkWriteException
+		if errors.As(err, &bulkErr) {
+			for _, writeErr := range bulkErr.WriteErrors {
+				if writeErr.Code == 11000 && aliased[writeErr.Index] {
+					failed[writeErr.Index] = "Alias is already taken"
+				} else if writeErr.Code == 11000 {
+					failed[writeErr.Index] = "Short url was taken while it was being created, try again"
+				} else {
+					failed[writeErr.Index] = "Failed to create url"
+				}
+			}
+		} else {
+			for n := range inserted {
+				failed[n] = "Failed to create url"
+			}
+		}
+	}
+
+	var revisions []interface{}
+	for n, i := range inserted {
+		if _, ok := failed[n]; !ok {
+			revisions = append(revisions, newRevision(&items[i].url, nil, items[i].url.UserId, models.RevisionActionCreate))
+		}
+	}
+	if len(revisions) > 0 {
+		if _, err := historyCollection.InsertMany(context.Background(), revisions); err != nil {
+			log.Println("Failed to record url revisions:", err)
+		}
+	}
+
+	if len(failed) == 0 {
+		return
+	}
+
+	// Release the codes of documents that were never saved
+	pipe = database.RDB.Pipeline()
+	for n, i := range inserted {
+		if message, ok := failed[n]; ok {
+			items[i].err = message
+			pipe.Del(ctx, redisKey(domain, items[i].shortURL))
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Println("Redis pipeline error:", err)
+	}
+}
+
+// takenShortURLs is the batch version of isShortUrlTaken
+func takenShortURLs(domain string, codes []string) (map[string]bool, error) {
+	taken := make(map[string]bool)
+	if len(codes) == 0 {
+		return taken, nil
+	}
+
+	filter := codeFilter(domain, "")
+	filter["shortUrl"] = bson.M{"$in": codes}
+
+	cursor, err := urlCollection.Find(
+		context.Background(),
+		filter,
+		options.Find().SetProjection(bson.M{"shortUrl": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	for cursor.Next(context.Background()) {
+		var url models.Url
+		if err := cursor.Decode(&url); err != nil {
+			return nil, err
+		}
+		taken[url.ShortUrl] = true
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	pipe := database.RDB.Pipeline()
+	exists := make([]*redis.IntCmd, len(codes))
+	for i, code := range codes {
+		exists[i] = pipe.Exists(ctx, redisKey(domain, code))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	for i, code := range codes {
+		if exists[i].Val() > 0 {
+			taken[code] = true
+		}
+	}
+
+	return taken, nil
+}
+
+func failPendingBulkItems(items []bulkItem, message string) {
+	for i := range items {
+		if items[i].err == "" {
+			items[i].err = message
+		}
+	}
+}
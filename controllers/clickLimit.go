@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/manlikehenryy/url-shortener-go/database"
+	"github.com/manlikehenryy/url-shortener-go/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// consumeClickScript counts a click against a limit in one atomic step, so
+// concurrent clicks can never go over it. It returns the new count, or -1
+// without counting when the limit has already been reached. The counter
+// has no expiry, since the url's key can be extended or rewritten after it
+// was set, and is deleted when the url is purged from the trash.
+var consumeClickScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count > tonumber(ARGV[1]) then
+	redis.call('DECR', KEYS[1])
+	return -1
+end
+return count
+`)
+
+// clickCounterKey is the click counter of the url stored under key
+func clickCounterKey(key string) string {
+	return "clicks:" + key
+}
+
+// consumeClick records a click on a click-limited url, ok is false once
+// the limit has been used up
+func consumeClick(url *models.Url) (count int64, ok bool, err error) {
+	count, err = consumeClickScript.Run(
+		ctx,
+		database.RDB,
+		[]string{clickCounterKey(urlRedisKey(url))},
+		url.MaxClicks,
+	).Int64()
+	if err != nil {
+		return 0, false, err
+	}
+
+	return count, count >= 0, nil
+}
+
+// markExhausted records when a url ran out of clicks so its status can be
+// reported without reading the counter
+func markExhausted(url *models.Url) error {
+	if url.ExhaustedAt != nil {
+		return nil
+	}
+
+	_, err := urlCollection.UpdateOne(
+		context.Background(),
+		bson.M{"_id": url.ID, "exhaustedAt": nil},
+		bson.M{"$set": bson.M{"exhaustedAt": time.Now()}},
+	)
+	return err
+}
+
+// exhaustedAtFor works out exhaustedAt after a url's click limit changes
+func exhaustedAtFor(url *models.Url, current *time.Time) (*time.Time, error) {
+	if url.MaxClicks <= 0 {
+		return nil, nil
+	}
+
+	count, err := database.RDB.Get(ctx, clickCounterKey(urlRedisKey(url))).Int64()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if count < url.MaxClicks {
+		return nil, nil
+	}
+	if current != nil {
+		return current, nil
+	}
+
+	now := time.Now()
+	return &now, nil
+}
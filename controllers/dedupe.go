@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/manlikehenryy/url-shortener-go/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Only the most recent links for a destination are checked for one that
+// is still active
+const maxDedupeCandidates = 20
+
+// shouldDedupe decides whether a create request reuses an existing link.
+// The request's dedupe flag wins over the account setting, and a request
+// for a specific alias always gets that alias. An existing link may not
+// have the options asked for, such as a password or click limit, so only
+// requests without any are deduplicated.
+func shouldDedupe(url *models.Url) (bool, error) {
+	if url.Alias != "" || hasLinkOptions(url) {
+		return false, nil
+	}
+	if url.Dedupe != nil {
+		return *url.Dedupe, nil
+	}
+
+	user, err := findUser(url.UserId)
+	if err != nil {
+		return false, err
+	}
+	return user.DedupeUrls, nil
+}
+
+// hasLinkOptions reports whether a validated create request sets anything
+// besides its destination and domain
+func hasLinkOptions(url *models.Url) bool {
+	return (url.Password != nil && *url.Password != "") ||
+		url.MaxClicks > 0 ||
+		url.ActiveFrom != nil ||
+		url.ExpirationType != models.ExpirationNever ||
+		url.RedirectType != http.StatusFound ||
+		url.QueryPassthrough != models.QueryPassthroughOff ||
+		url.Utm != nil ||
+		url.DeepLinks != nil ||
+		len(url.GeoTargets) > 0 ||
+		len(url.Variants) > 0 ||
+		len(url.ScheduleRules) > 0 ||
+		url.Title != "" ||
+		url.ForcePreview ||
+		url.FallbackUrl != "" ||
+		url.SocialCard != nil
+}
+
+// findActiveDuplicate returns the user's newest active link on a domain
+// for a normalized destination, or nil when there is none
+func findActiveDuplicate(userId primitive.ObjectID, domain string, originalUrl string) (*models.Url, error) {
+	filter := bson.M{"userId": userId, "originalUrl": originalUrl, "deletedAt": nil, "domain": nil}
+	if domain != "" {
+		filter["domain"] = domain
+	}
+
+	cursor, err := urlCollection.Find(
+		context.Background(),
+		filter,
+		options.Find().
+			SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+			SetLimit(maxDedupeCandidates),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	for cursor.Next(context.Background()) {
+		var url models.Url
+		if err := cursor.Decode(&url); err != nil {
+			return nil, err
+		}
+
+		active, err := isUrlActive(&url)
+		if err != nil {
+			return nil, err
+		}
+		if active {
+			return &url, nil
+		}
+	}
+
+	return nil, cursor.Err()
+}
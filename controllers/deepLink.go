@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"github.com/manlikehenryy/url-shortener-go/helpers"
+	"github.com/manlikehenryy/url-shortener-go/models"
+)
+
+// validateDeepLinks checks a url's per-platform destinations, store and
+// desktop urls, and http app urls, go through the same checks as originalUrl
+func validateDeepLinks(url *models.Url) helpers.FieldErrors {
+	fieldErrors := helpers.FieldErrors{}
+	links := url.DeepLinks
+	if links == nil {
+		return fieldErrors
+	}
+
+	if links.Desktop != "" {
+		desktop, err := checkDestination(links.Desktop)
+		if err != nil {
+			fieldErrors["deepLinks.desktop"] = err.Error()
+		} else {
+			links.Desktop = desktop
+		}
+	}
+
+	for field, link := range map[string]*models.AppLink{"ios": links.IOS, "android": links.Android} {
+		if link == nil {
+			continue
+		}
+
+		if link.AppUrl != "" {
+			appUrl, err := helpers.ValidateAppURL(link.AppUrl)
+			if err == nil && helpers.IsWebURL(appUrl) {
+				// Universal links open in the browser when the app isn't
+				// installed, so they are held to the same rules as any
+				// other destination
+				appUrl, err = checkDestination(appUrl)
+			}
+			if err != nil {
+				fieldErrors["deepLinks."+field+".appUrl"] = err.Error()
+			} else {
+				link.AppUrl = appUrl
+			}
+		}
+
+		if link.StoreUrl != "" {
+			storeUrl, err := checkDestination(link.StoreUrl)
+			if err != nil {
+				fieldErrors["deepLinks."+field+".storeUrl"] = err.Error()
+			} else {
+				link.StoreUrl = storeUrl
+			}
+		}
+	}
+
+	if links.IOS != nil && *links.IOS == (models.AppLink{}) {
+		links.IOS = nil
+	}
+	if links.Android != nil && *links.Android == (models.AppLink{}) {
+		links.Android = nil
+	}
+	if *links == (models.DeepLinks{}) {
+		url.DeepLinks = nil
+	}
+
+	return fieldErrors
+}
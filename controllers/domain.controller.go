@@ -0,0 +1,370 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/manlikehenryy/url-shortener-go/configs"
+	"github.com/manlikehenryy/url-shortener-go/helpers"
+	"github.com/manlikehenryy/url-shortener-go/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	domainLookupTimeout   = 5 * time.Second
+	domainRefreshInterval = time.Minute
+)
+
+// verifiedDomains caches the verified hosts so redirects can tell which
+// domain they were visited on without a database round trip
+var verifiedDomains = &helpers.HostSet{}
+
+// loadVerifiedDomains refreshes the cached hosts from the database
+func loadVerifiedDomains() error {
+	cursor, err := domainsCollection.Find(
+		context.Background(),
+		bson.M{"verified": true},
+		options.Find().SetProjection(bson.M{"host": 1}),
+	)
+	if err != nil {
+		return err
+	}
+
+	var domains []models.Domain
+	if err := cursor.All(context.Background(), &domains); err != nil {
+		return err
+	}
+
+	hosts := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		hosts = append(hosts, domain.Host)
+	}
+	verifiedDomains.Load(hosts)
+	return nil
+}
+
+func reloadVerifiedDomains() {
+	if err := loadVerifiedDomains(); err != nil {
+		log.Println("Failed to load verified domains:", err)
+	}
+}
+
+type domainPayload struct {
+	Host string `json:"host" binding:"required"`
+}
+
+func GetAllDomains(c *gin.Context) {
+	userId, ok := c.MustGet("userId").(primitive.ObjectID)
+	if !ok {
+		helpers.SendError(c, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	cursor, err := domainsCollection.Find(
+		context.Background(),
+		bson.M{"userId": userId},
+		options.Find().SetSort(bson.D{{Key: "host", Value: 1}}),
+	)
+	if err != nil {
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to retrieve domains")
+		return
+	}
+
+	domains := []models.Domain{}
+	if err := cursor.All(context.Background(), &domains); err != nil {
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to retrieve domains")
+		return
+	}
+
+	helpers.SendJSON(c, http.StatusOK, gin.H{
+		"data":    domains,
+		"message": "Data fetched successfully",
+	})
+}
+
+// CreateDomain registers a domain and returns the TXT record that has to
+// be published before it can be verified
+func CreateDomain(c *gin.Context) {
+	userId, ok := c.MustGet("userId").(primitive.ObjectID)
+	if !ok {
+		helpers.SendError(c, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	var payload domainPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		log.Println("Unable to parse body:", err)
+		helpers.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	host, err := helpers.NormalizeDomain(payload.Host)
+	if err == nil && helpers.IsSelfHost(host, helpers.HostFromAppURL(configs.Env.APP_URL)) {
+		err = errors.New("is already this url shortener's domain")
+	}
+	if err != nil {
+		helpers.SendValidationError(c, helpers.FieldErrors{"host": err.Error()})
+		return
+	}
+
+	// Unverified claims don't block anyone, the first to verify wins
+	verified, err := domainsCollection.CountDocuments(context.Background(), bson.M{"host": host, "verified": true})
+	if err != nil {
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to create domain")
+		return
+	}
+	if verified > 0 {
+		helpers.SendError(c, http.StatusConflict, "Domain is already registered")
+		return
+	}
+
+	token, err := helpers.NewDomainVerificationToken()
+	if err != nil {
+		log.Println("Token generation error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to create domain")
+		return
+	}
+
+	domain := models.Domain{
+		Host:              host,
+		UserId:            userId,
+		VerificationToken: token,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+
+	insertResult, err := domainsCollection.InsertOne(context.Background(), domain)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			helpers.SendError(c, http.StatusConflict, "You have already added this domain")
+			return
+		}
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to create domain")
+		return
+	}
+
+	domain.ID = insertResult.InsertedID.(primitive.ObjectID)
+
+	helpers.SendJSON(c, http.StatusCreated, gin.H{
+		"data":         domain,
+		"verification": verificationRecord(&domain),
+		"message":      "Domain created, publish the TXT record and verify it",
+	})
+}
+
+// VerifyDomain checks the domain's TXT record, links can be created on it
+// once it is verified
+func VerifyDomain(c *gin.Context) {
+	domain, ok := findOwnedDomain(c)
+	if !ok {
+		return
+	}
+
+	if domain.Verified {
+		helpers.SendJSON(c, http.StatusOK, gin.H{
+			"data":    domain,
+			"message": "Domain is already verified",
+		})
+		return
+	}
+
+	taken, err := domainsCollection.CountDocuments(context.Background(), bson.M{"host": domain.Host, "verified": true})
+	if err != nil {
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to verify domain")
+		return
+	}
+	if taken > 0 {
+		helpers.SendError(c, http.StatusConflict, "Domain has already been verified by another account")
+		return
+	}
+
+	lookupCtx, cancel := context.WithTimeout(context.Background(), domainLookupTimeout)
+	defer cancel()
+
+	verified, err := helpers.VerifyDomain(lookupCtx, helpers.DomainResolver, domain.Host, domain.VerificationToken)
+	if err != nil {
+		log.Println("DNS lookup error:", err)
+		helpers.SendError(c, http.StatusBadGateway, "Failed to look up the verification record")
+		return
+	}
+
+	if !verified {
+		helpers.SendJSON(c, http.StatusUnprocessableEntity, gin.H{
+			"error":        "Verification record not found",
+			"verification": verificationRecord(&domain),
+		})
+		return
+	}
+
+	now := time.Now()
+	_, err = domainsCollection.UpdateOne(
+		context.Background(),
+		bson.M{"_id": domain.ID},
+		bson.M{"$set": bson.M{"verified": true, "verifiedAt": now, "updatedAt": now}},
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		// Another account verified it in the meantime
+		helpers.SendError(c, http.StatusConflict, "Domain has already been verified by another account")
+		return
+	} else if err != nil {
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to verify domain")
+		return
+	}
+
+	domain.Verified = true
+	domain.VerifiedAt = &now
+	domain.UpdatedAt = now
+	reloadVerifiedDomains()
+
+	helpers.SendJSON(c, http.StatusOK, gin.H{
+		"data":    domain,
+		"message": "Domain verified successfully",
+	})
+}
+
+// DeleteDomain removes a domain that no longer has any links, an
+// unverified claim can always be removed
+func DeleteDomain(c *gin.Context) {
+	domain, ok := findOwnedDomain(c)
+	if !ok {
+		return
+	}
+
+	var count int64
+	if domain.Verified {
+		var err error
+		count, err = urlCollection.CountDocuments(context.Background(), bson.M{"domain": domain.Host})
+		if err != nil {
+			log.Println("Database error:", err)
+			helpers.SendError(c, http.StatusInternalServerError, "Failed to delete domain")
+			return
+		}
+	}
+	if count > 0 {
+		helpers.SendError(c, http.StatusConflict, "Domain still has links, including any in the trash")
+		return
+	}
+
+	if _, err := domainsCollection.DeleteOne(context.Background(), bson.M{"_id": domain.ID}); err != nil {
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to delete domain")
+		return
+	}
+
+	if domain.Verified {
+		reloadVerifiedDomains()
+	}
+
+	helpers.SendJSON(c, http.StatusOK, gin.H{
+		"message": "Domain deleted successfully",
+	})
+}
+
+func verificationRecord(domain *models.Domain) gin.H {
+	name, value := helpers.DomainVerificationRecord(domain.Host, domain.VerificationToken)
+	return gin.H{
+		"type":  "TXT",
+		"name":  name,
+		"value": value,
+	}
+}
+
+// findOwnedDomain loads the domain in the :id param for its owner, sending
+// the error response itself when it can't
+func findOwnedDomain(c *gin.Context) (models.Domain, bool) {
+	var domain models.Domain
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		helpers.SendError(c, http.StatusBadRequest, "Invalid domain ID")
+		return domain, false
+	}
+
+	userId, ok := c.MustGet("userId").(primitive.ObjectID)
+	if !ok {
+		helpers.SendError(c, http.StatusUnauthorized, "User ID not found in context")
+		return domain, false
+	}
+
+	err = domainsCollection.FindOne(context.Background(), bson.M{"_id": id, "userId": userId}).Decode(&domain)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			helpers.SendError(c, http.StatusNotFound, "Domain not found")
+		} else {
+			helpers.SendError(c, http.StatusInternalServerError, "Failed to retrieve domain")
+		}
+		return domain, false
+	}
+
+	return domain, true
+}
+
+// urlDomain checks the domain a user picked for a new url, "" is the
+// shortener's own domain
+func urlDomain(userId primitive.ObjectID, host string) (string, error) {
+	if host == "" {
+		return "", nil
+	}
+
+	host, err := helpers.NormalizeDomain(host)
+	if err != nil {
+		return "", err
+	}
+
+	count, err := domainsCollection.CountDocuments(context.Background(), bson.M{"host": host, "userId": userId, "verified": true})
+	if err != nil {
+		log.Println("Database error:", err)
+		return "", errors.New("could not be checked, try again")
+	}
+	if count == 0 {
+		return "", errors.New("must be one of your verified domains")
+	}
+
+	return host, nil
+}
+
+// isShortenerHost reports whether host (with an optional port) serves this
+// url shortener, either as APP_URL or as a verified domain
+func isShortenerHost(host string) bool {
+	if helpers.IsSelfHost(host, helpers.HostFromAppURL(configs.Env.APP_URL)) {
+		return true
+	}
+
+	host = strings.ToLower(host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return verifiedDomains.Has(strings.TrimSuffix(host, "."))
+}
+
+// requestDomain is the domain a short link was visited on, "" for the
+// shortener's own domain and any host that isn't a verified domain
+func requestDomain(c *gin.Context) string {
+	host := strings.ToLower(c.Request.Host)
+	if helpers.IsSelfHost(host, helpers.HostFromAppURL(configs.Env.APP_URL)) {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	if !verifiedDomains.Has(host) {
+		return ""
+	}
+	return host
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/manlikehenryy/url-shortener-go/helpers"
+	"github.com/manlikehenryy/url-shortener-go/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// sendMissingLink answers a visit to a code with no live Redis entry. The
+// url may still be stored if it has expired, otherwise the code is unknown.
+func sendMissingLink(c *gin.Context, domain string, shortURL string) {
+	filter := codeFilter(domain, shortURL)
+	filter["deletedAt"] = nil
+
+	var url models.Url
+	err := urlCollection.FindOne(context.Background(), filter).Decode(&url)
+	if err == nil {
+		sendUnavailableLink(c, &url, domain, http.StatusNotFound, "URL not found or expired",
+			"Link expired", "This link has expired and is no longer available.")
+		return
+	}
+
+	if err != mongo.ErrNoDocuments {
+		log.Println("Database error:", err)
+	}
+	sendUnavailableLink(c, nil, domain, http.StatusNotFound, "URL not found or expired",
+		"Link not found", "This link doesn't exist. Check that it was copied correctly.")
+}
+
+// sendUnavailableLink sends the visitor to the fallback for a link that
+// can't be followed. Without one browsers get a page and API clients the
+// JSON error. url is nil for unknown codes.
+func sendUnavailableLink(c *gin.Context, url *models.Url, domain string, statusCode int, jsonError string, title string, message string) {
+	// Rules may have been added since the fallback was saved
+	if fallback := fallbackFor(url, domain); fallback != "" && destinationPolicy.Check(fallback) == nil {
+		c.Redirect(http.StatusFound, fallback)
+		return
+	}
+
+	if helpers.WantsJSON(c) {
+		helpers.SendError(c, statusCode, jsonError)
+		return
+	}
+	helpers.SendMessagePage(c, statusCode, title, message)
+}
+
+// fallbackFor is the url's own fallbackUrl, or else the account fallback of
+// whoever owns the url or, for unknown codes, the branded domain
+func fallbackFor(url *models.Url, domain string) string {
+	if url != nil && url.FallbackUrl != "" {
+		return url.FallbackUrl
+	}
+
+	var ownerId primitive.ObjectID
+	switch {
+	case url != nil:
+		ownerId = url.UserId
+	case domain != "":
+		var owner models.Domain
+		err := domainsCollection.FindOne(context.Background(), bson.M{"host": domain, "verified": true}).Decode(&owner)
+		if err != nil {
+			if err != mongo.ErrNoDocuments {
+				log.Println("Database error:", err)
+			}
+			return ""
+		}
+		ownerId = owner.UserId
+	default:
+		return ""
+	}
+
+	user, err := findUser(ownerId)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Println("Database error:", err)
+		}
+		return ""
+	}
+	return user.FallbackUrl
+}
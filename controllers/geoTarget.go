@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/manlikehenryy/url-shortener-go/helpers"
+	"github.com/manlikehenryy/url-shortener-go/models"
+)
+
+const maxGeoTargets = 250
+
+var countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// validateGeoTargets checks a url's country destinations, keys are
+// upper-cased ISO 3166-1 alpha-2 codes
+func validateGeoTargets(url *models.Url) helpers.FieldErrors {
+	fieldErrors := helpers.FieldErrors{}
+	if len(url.GeoTargets) == 0 {
+		url.GeoTargets = nil
+		return fieldErrors
+	}
+
+	if len(url.GeoTargets) > maxGeoTargets {
+		fieldErrors["geoTargets"] = fmt.Sprintf("must have at most %d countries", maxGeoTargets)
+		return fieldErrors
+	}
+
+	targets := map[string]string{}
+	for country, destination := range url.GeoTargets {
+		code := strings.ToUpper(strings.TrimSpace(country))
+		if !countryCodePattern.MatchString(code) {
+			fieldErrors["geoTargets."+country] = "must be keyed by a two-letter country code"
+			continue
+		}
+
+		normalized, err := checkDestination(destination)
+		if err != nil {
+			fieldErrors["geoTargets."+country] = err.Error()
+			continue
+		}
+		targets[code] = normalized
+	}
+	url.GeoTargets = targets
+
+	return fieldErrors
+}
+
+// geoTarget is the destination for the visitor's country, if the url has one
+func geoTarget(c *gin.Context, url *models.Url) (string, bool) {
+	if len(url.GeoTargets) == 0 {
+		return "", false
+	}
+
+	country := helpers.CountryForIP(helpers.GetClientIP(c))
+	if country == "" {
+		return "", false
+	}
+
+	destination, ok := url.GeoTargets[country]
+	return destination, ok
+}
@@ -0,0 +1,267 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/manlikehenryy/url-shortener-go/database"
+	"github.com/manlikehenryy/url-shortener-go/helpers"
+	"github.com/manlikehenryy/url-shortener-go/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// newRevision snapshots a url after a change, previous is the state before
+// it and is nil for a new url
+func newRevision(url *models.Url, previous *models.Url, changedBy primitive.ObjectID, action string) models.UrlRevision {
+	return models.UrlRevision{
+		UrlId:            url.ID,
+		Version:          url.Version,
+		Action:           action,
+		Changes:          changedUrlFields(previous, url),
+		OriginalUrl:      url.OriginalUrl,
+		Expiration:       url.Expiration,
+		ExpirationType:   url.ExpirationType,
+		ExpiresAt:        url.ExpiresAt,
+		RedirectType:     url.RedirectType,
+		QueryPassthrough: url.QueryPassthrough,
+		Utm:              url.Utm,
+		DeepLinks:        url.DeepLinks,
+		GeoTargets:       url.GeoTargets,
+		Variants:         url.Variants,
+		StickyVariants:   url.StickyVariants,
+		ScheduleRules:    url.ScheduleRules,
+		ChangedBy:        changedBy,
+		ChangedAt:        time.Now(),
+	}
+}
+
+// recordRevision saves a revision. The change itself has already been made
+// by then, so a failure is only logged.
+func recordRevision(revision models.UrlRevision) {
+	if _, err := historyCollection.InsertOne(context.Background(), revision); err != nil {
+		log.Println("Failed to record url revision:", err)
+	}
+}
+
+func changedUrlFields(before *models.Url, after *models.Url) []string {
+	changes := []string{}
+	if before == nil || before.OriginalUrl != after.OriginalUrl {
+		changes = append(changes, "originalUrl")
+	}
+	if before == nil || before.ExpirationType != after.ExpirationType {
+		changes = append(changes, "expirationType")
+	}
+	if before == nil || before.Expiration != after.Expiration {
+		changes = append(changes, "expiration")
+	}
+	if before == nil || !sameTime(before.ExpiresAt, after.ExpiresAt) {
+		changes = append(changes, "expiresAt")
+	}
+
+	// The other destination settings are only listed for a new url when
+	// they are set
+	previous := before
+	if previous == nil {
+		previous = &models.Url{}
+	}
+	for _, field := range []struct {
+		name string
+		same bool
+	}{
+		{"redirectType", previous.RedirectType == after.RedirectType},
+		{"queryPassthrough", previous.QueryPassthrough == after.QueryPassthrough},
+		{"utm", reflect.DeepEqual(previous.Utm, after.Utm)},
+		{"deepLinks", reflect.DeepEqual(previous.DeepLinks, after.DeepLinks)},
+		{"geoTargets", reflect.DeepEqual(previous.GeoTargets, after.GeoTargets)},
+		{"variants", reflect.DeepEqual(previous.Variants, after.Variants)},
+		{"stickyVariants", previous.StickyVariants == after.StickyVariants},
+		{"scheduleRules", reflect.DeepEqual(previous.ScheduleRules, after.ScheduleRules)},
+	} {
+		if !field.same {
+			changes = append(changes, field.name)
+		}
+	}
+	return changes
+}
+
+func sameTime(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	// Mongo stores milliseconds
+	return a.Truncate(time.Millisecond).Equal(b.Truncate(time.Millisecond))
+}
+
+func GetUrlHistory(c *gin.Context) {
+	existingUrl, ok := findOwnedUrl(c)
+	if !ok {
+		return
+	}
+
+	cursor, err := historyCollection.Find(
+		context.Background(),
+		bson.M{"urlId": existingUrl.ID},
+		options.Find().SetSort(bson.D{{Key: "version", Value: -1}}),
+	)
+	if err != nil {
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to retrieve url history")
+		return
+	}
+
+	revisions := []models.UrlRevision{}
+	if err := cursor.All(context.Background(), &revisions); err != nil {
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to retrieve url history")
+		return
+	}
+
+	helpers.SendJSON(c, http.StatusOK, gin.H{
+		"data":    revisions,
+		"message": "Data fetched successfully",
+	})
+}
+
+// RollbackUrl restores the destination settings and expiry of an earlier
+// version.
+// The rollback is itself recorded as a new version.
+func RollbackUrl(c *gin.Context) {
+	existingUrl, ok := findOwnedUrl(c)
+	if !ok {
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		helpers.SendError(c, http.StatusBadRequest, "Invalid version")
+		return
+	}
+
+	var revision models.UrlRevision
+	err = historyCollection.FindOne(context.Background(), bson.M{"urlId": existingUrl.ID, "version": version}).Decode(&revision)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			helpers.SendError(c, http.StatusNotFound, "Version not found")
+		} else {
+			helpers.SendError(c, http.StatusInternalServerError, "Failed to retrieve url history")
+		}
+		return
+	}
+
+	url := existingUrl
+	url.OriginalUrl = revision.OriginalUrl
+	url.Expiration = revision.Expiration
+	url.ExpirationType = revision.ExpirationType
+	url.ExpiresAt = revision.ExpiresAt
+	url.RedirectType = revision.RedirectType
+	url.QueryPassthrough = revision.QueryPassthrough
+	url.Utm = revision.Utm
+	url.DeepLinks = revision.DeepLinks
+	url.GeoTargets = revision.GeoTargets
+	url.Variants = revision.Variants
+	url.StickyVariants = revision.StickyVariants
+	url.ScheduleRules = revision.ScheduleRules
+
+	// The old destination has to pass today's checks, including the policy
+	if fieldErrors := validateUrlFields(&url); len(fieldErrors) > 0 {
+		helpers.SendValidationError(c, fieldErrors)
+		return
+	}
+
+	if fieldErrors := checkRedirectLoop(&url); len(fieldErrors) > 0 {
+		helpers.SendValidationError(c, fieldErrors)
+		return
+	}
+
+	err = database.RDB.Set(ctx, urlRedisKey(&existingUrl), url.OriginalUrl, redisTTL(&url)).Err()
+	if err != nil {
+		log.Println(err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to update URL")
+		return
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"originalUrl":      url.OriginalUrl,
+			"expiration":       url.Expiration,
+			"expirationType":   url.ExpirationType,
+			"expiresAt":        url.ExpiresAt,
+			"redirectType":     url.RedirectType,
+			"queryPassthrough": url.QueryPassthrough,
+			"utm":              url.Utm,
+			"deepLinks":        url.DeepLinks,
+			"geoTargets":       url.GeoTargets,
+			"variants":         url.Variants,
+			"stickyVariants":   url.StickyVariants,
+			"scheduleRules":    url.ScheduleRules,
+			"updatedAt":        time.Now(),
+		},
+		"$inc": bson.M{"version": 1},
+	}
+
+	var updatedUrl models.Url
+	err = urlCollection.FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": existingUrl.ID, "deletedAt": nil},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updatedUrl)
+	if err != nil {
+		log.Println("Database error:", err)
+		if err == mongo.ErrNoDocuments {
+			helpers.SendError(c, http.StatusNotFound, "Url not found")
+		} else {
+			helpers.SendError(c, http.StatusInternalServerError, "Failed to update url")
+		}
+		return
+	}
+
+	rollback := newRevision(&updatedUrl, &existingUrl, existingUrl.UserId, models.RevisionActionRollback)
+	rollback.RolledBackTo = version
+	recordRevision(rollback)
+
+	updatedUrl.Status = updatedUrl.ComputeStatus(time.Now())
+
+	helpers.SendJSON(c, http.StatusOK, gin.H{
+		"data":    updatedUrl,
+		"message": "Url rolled back successfully",
+	})
+}
+
+// findOwnedUrl loads the url in the :id param for its owner, sending the
+// error response itself when it can't
+func findOwnedUrl(c *gin.Context) (models.Url, bool) {
+	var url models.Url
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		helpers.SendError(c, http.StatusBadRequest, "Invalid url ID")
+		return url, false
+	}
+
+	userId, ok := c.MustGet("userId").(primitive.ObjectID)
+	if !ok {
+		helpers.SendError(c, http.StatusUnauthorized, "User ID not found in context")
+		return url, false
+	}
+
+	err = urlCollection.FindOne(context.Background(), bson.M{"_id": id, "userId": userId, "deletedAt": nil}).Decode(&url)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			helpers.SendError(c, http.StatusNotFound, "Url not found")
+		} else {
+			helpers.SendError(c, http.StatusInternalServerError, "Failed to retrieve url")
+		}
+		return url, false
+	}
+
+	return url, true
+}
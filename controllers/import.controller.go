@@ -0,0 +1,399 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/manlikehenryy/url-shortener-go/helpers"
+	"github.com/manlikehenryy/url-shortener-go/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const maxImportLinks = 10000
+
+var errImportAlreadyRun = errors.New("import has already been run")
+
+// ImportUrls reads an export file from another shortener and stores a dry
+// run report of what would be created. Links are only created when the
+// import is run, or straight away with ?dryRun=false.
+func ImportUrls(c *gin.Context) {
+	userId, ok := c.MustGet("userId").(primitive.ObjectID)
+	if !ok {
+		helpers.SendError(c, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	format := c.PostForm("format")
+	if format == "" {
+		format = c.Query("format")
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		helpers.SendError(c, http.StatusBadRequest, "Export file is required")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		helpers.SendError(c, http.StatusBadRequest, "Unable to read export file")
+		return
+	}
+	defer file.Close()
+
+	links, err := helpers.ParseImportFile(format, file)
+	if err != nil {
+		log.Println("Unable to parse import file:", err)
+		helpers.SendError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(links) == 0 {
+		helpers.SendError(c, http.StatusBadRequest, "No links found in export file")
+		return
+	}
+
+	if len(links) > maxImportLinks {
+		helpers.SendError(c, http.StatusBadRequest, fmt.Sprintf("An import can contain at most %d links", maxImportLinks))
+		return
+	}
+
+	// Imported links all go on one domain
+	domain := c.PostForm("domain")
+	if domain == "" {
+		domain = c.Query("domain")
+	}
+	domain, err = urlDomain(userId, domain)
+	if err != nil {
+		helpers.SendValidationError(c, helpers.FieldErrors{"domain": err.Error()})
+		return
+	}
+
+	items, err := planImport(domain, links)
+	if err != nil {
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to check short urls")
+		return
+	}
+
+	job := models.ImportJob{
+		UserId:    userId,
+		Domain:    domain,
+		Format:    format,
+		FileName:  fileHeader.Filename,
+		Status:    models.ImportStatusPending,
+		Summary:   summarizeImport(items, false),
+		Items:     items,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	insertResult, err := importsCollection.InsertOne(context.Background(), job)
+	if err != nil {
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to create import")
+		return
+	}
+
+	job.ID = insertResult.InsertedID.(primitive.ObjectID)
+
+	if c.DefaultQuery("dryRun", "true") == "false" {
+		if err := runImport(&job); err == errImportAlreadyRun {
+			helpers.SendError(c, http.StatusConflict, "Import has already been run")
+			return
+		} else if err != nil {
+			log.Println("Import error:", err)
+			helpers.SendError(c, http.StatusInternalServerError, "Failed to run import")
+			return
+		}
+
+		helpers.SendJSON(c, http.StatusCreated, gin.H{
+			"data":    job,
+			"message": "Import completed",
+		})
+		return
+	}
+
+	helpers.SendJSON(c, http.StatusCreated, gin.H{
+		"data":    job,
+		"message": "Import report created, run the import to create the links",
+	})
+}
+
+func GetImport(c *gin.Context) {
+	job, ok := findImportJob(c)
+	if !ok {
+		return
+	}
+
+	helpers.SendJSON(c, http.StatusOK, gin.H{
+		"data": job,
+	})
+}
+
+func RunImport(c *gin.Context) {
+	job, ok := findImportJob(c)
+	if !ok {
+		return
+	}
+
+	if job.Status != models.ImportStatusPending {
+		helpers.SendError(c, http.StatusConflict, "Import has already been run")
+		return
+	}
+
+	if err := runImport(&job); err == errImportAlreadyRun {
+		helpers.SendError(c, http.StatusConflict, "Import has already been run")
+		return
+	} else if err != nil {
+		log.Println("Import error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to run import")
+		return
+	}
+
+	helpers.SendJSON(c, http.StatusOK, gin.H{
+		"data":    job,
+		"message": "Import completed",
+	})
+}
+
+func findImportJob(c *gin.Context) (models.ImportJob, bool) {
+	var job models.ImportJob
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		helpers.SendError(c, http.StatusBadRequest, "Invalid import ID")
+		return job, false
+	}
+
+	userId, ok := c.MustGet("userId").(primitive.ObjectID)
+	if !ok {
+		helpers.SendError(c, http.StatusUnauthorized, "User ID not found in context")
+		return job, false
+	}
+
+	err = importsCollection.FindOne(context.Background(), bson.M{"_id": id, "userId": userId}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			helpers.SendError(c, http.StatusNotFound, "Import not found")
+		} else {
+			helpers.SendError(c, http.StatusInternalServerError, "Failed to retrieve import")
+		}
+		return job, false
+	}
+
+	return job, true
+}
+
+// planImport decides for every link whether its original code can be kept
+// as an alias, needs a new code or has to be skipped
+func planImport(domain string, links []helpers.ImportedLink) ([]models.ImportItem, error) {
+	items := make([]models.ImportItem, len(links))
+	firstUse := make(map[string]int)
+	var candidates []string
+
+	for i, link := range links {
+		item := &items[i]
+		*item = models.ImportItem{
+			Row:         link.Row,
+			OriginalUrl: link.OriginalUrl,
+			SourceCode:  link.ShortCode,
+			Expiration:  link.Expiration,
+			Action:      models.ImportActionSkip,
+		}
+
+		if link.Error != "" {
+			item.Message = link.Error
+			continue
+		}
+
+		if link.OriginalUrl == "" {
+			item.Message = "Destination url is missing"
+			continue
+		}
+
+		url := models.Url{OriginalUrl: link.OriginalUrl, Expiration: link.Expiration}
+		if err := validateNewUrl(&url); err != nil {
+			item.Message = err.Error()
+			continue
+		}
+
+		item.OriginalUrl = url.OriginalUrl
+		item.Action = models.ImportActionNewCode
+
+		if link.ShortCode == "" {
+			continue
+		}
+
+		if err := helpers.ValidateAlias(link.ShortCode); err != nil {
+			item.Message = "Original code can't be kept: " + err.Error()
+			continue
+		}
+
+		if _, ok := firstUse[link.ShortCode]; !ok {
+			firstUse[link.ShortCode] = i
+			candidates = append(candidates, link.ShortCode)
+		}
+	}
+
+	taken, err := takenShortURLs(domain, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		item := &items[i]
+		if item.Action != models.ImportActionNewCode || item.SourceCode == "" || item.Message != "" {
+			continue
+		}
+
+		switch {
+		case firstUse[item.SourceCode] != i:
+			item.Message = "Original code appears more than once in the file"
+		case taken[item.SourceCode]:
+			item.Message = "Original code is already taken"
+		default:
+			item.Action = models.ImportActionKeepCode
+			item.ShortUrl = item.SourceCode
+		}
+	}
+
+	return items, nil
+}
+
+// runImport re-checks the plan, since codes may have been taken since the
+// dry run, then creates the links and saves the outcome on the job. The job
+// is claimed first so two requests can't create its links twice, it returns
+// errImportAlreadyRun when the job isn't pending anymore.
+func runImport(job *models.ImportJob) error {
+	err := importsCollection.FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": job.ID, "status": models.ImportStatusPending},
+		bson.M{"$set": bson.M{"status": models.ImportStatusRunning, "updatedAt": time.Now()}},
+	).Err()
+	if err == mongo.ErrNoDocuments {
+		return errImportAlreadyRun
+	} else if err != nil {
+		return err
+	}
+
+	links := make([]helpers.ImportedLink, len(job.Items))
+	for i, item := range job.Items {
+		links[i] = helpers.ImportedLink{
+			Row:         item.Row,
+			OriginalUrl: item.OriginalUrl,
+			ShortCode:   item.SourceCode,
+			Expiration:  item.Expiration,
+		}
+		if item.Action == models.ImportActionSkip {
+			links[i].Error = item.Message
+		}
+	}
+
+	items, err := planImport(job.Domain, links)
+	if err != nil {
+		// Nothing was created, the import can be run again
+		if _, resetErr := importsCollection.UpdateOne(
+			context.Background(),
+			bson.M{"_id": job.ID},
+			bson.M{"$set": bson.M{"status": models.ImportStatusPending}},
+		); resetErr != nil {
+			log.Println("Database error:", resetErr)
+		}
+		return err
+	}
+
+	var batch []bulkItem
+	var positions []int
+	for i, item := range items {
+		if item.Action == models.ImportActionSkip {
+			continue
+		}
+
+		url := models.Url{
+			OriginalUrl: item.OriginalUrl,
+			Expiration:  item.Expiration,
+			UserId:      job.UserId,
+		}
+		if item.Action == models.ImportActionKeepCode {
+			url.Alias = item.SourceCode
+		}
+
+		// Fills in the expiry the links are stored with
+		if err := validateNewUrl(&url); err != nil {
+			items[i].Action = models.ImportActionSkip
+			items[i].Message = err.Error()
+			continue
+		}
+
+		batch = append(batch, bulkItem{url: url})
+		positions = append(positions, i)
+	}
+
+	storeBulkUrls(job.Domain, batch)
+
+	for n, i := range positions {
+		result := &batch[n]
+		item := &items[i]
+		if result.err != "" {
+			item.Message = result.err
+			continue
+		}
+
+		id := result.url.ID
+		item.UrlId = &id
+		item.ShortUrl = result.shortURL
+		item.Created = true
+	}
+
+	now := time.Now()
+	job.Items = items
+	job.Summary = summarizeImport(items, true)
+	job.Status = models.ImportStatusCompleted
+	job.CompletedAt = &now
+	job.UpdatedAt = now
+
+	_, err = importsCollection.UpdateOne(
+		context.Background(),
+		bson.M{"_id": job.ID},
+		bson.M{"$set": bson.M{
+			"items":       job.Items,
+			"summary":     job.Summary,
+			"status":      job.Status,
+			"completedAt": job.CompletedAt,
+			"updatedAt":   job.UpdatedAt,
+		}},
+	)
+	return err
+}
+
+func summarizeImport(items []models.ImportItem, ran bool) models.ImportSummary {
+	summary := models.ImportSummary{Total: len(items)}
+
+	for _, item := range items {
+		switch item.Action {
+		case models.ImportActionKeepCode:
+			summary.KeepCode++
+		case models.ImportActionNewCode:
+			summary.NewCode++
+		case models.ImportActionSkip:
+			summary.Skipped++
+			continue
+		}
+
+		if item.Created {
+			summary.Created++
+		} else if ran {
+			summary.Failed++
+		}
+	}
+
+	return summary
+}
@@ -1,13 +1,87 @@
 package controllers
 
-import "go.mongodb.org/mongo-driver/mongo"
+import (
+	"context"
+	"errors"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
 
 var usersCollection *mongo.Collection
 var urlCollection *mongo.Collection
-
+var importsCollection *mongo.Collection
+var policiesCollection *mongo.Collection
+var historyCollection *mongo.Collection
+var domainsCollection *mongo.Collection
 
 func InitDB(DB *mongo.Database) {
 
 	usersCollection = DB.Collection("users")
 	urlCollection = DB.Collection("url")
+	importsCollection = DB.Collection("imports")
+	policiesCollection = DB.Collection("policies")
+	historyCollection = DB.Collection("url_history")
+	domainsCollection = DB.Collection("domains")
+
+	// Short codes must be unique on each domain so one link can never
+	// shadow another. This replaces the unique index on shortUrl alone.
+	createIndex(urlCollection, mongo.IndexModel{
+		Keys:    bson.D{{Key: "domain", Value: 1}, {Key: "shortUrl", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	dropIndex(urlCollection, "shortUrl_1")
+	// Used by the trash purge
+	createIndex(urlCollection, mongo.IndexModel{
+		Keys:    bson.D{{Key: "deletedAt", Value: 1}},
+		Options: options.Index().SetSparse(true),
+	})
+	// Used to find a user's existing link for a destination
+	createIndex(urlCollection, mongo.IndexModel{
+		Keys: bson.D{{Key: "userId", Value: 1}, {Key: "originalUrl", Value: 1}},
+	})
+	// One revision per version of a url
+	createIndex(historyCollection, mongo.IndexModel{
+		Keys:    bson.D{{Key: "urlId", Value: 1}, {Key: "version", Value: -1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Anyone can claim a domain, only one claim can be verified. This
+	// replaces the unique index on host alone.
+	createIndex(domainsCollection, mongo.IndexModel{
+		Keys:    bson.D{{Key: "host", Value: 1}, {Key: "userId", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	createIndex(domainsCollection, mongo.IndexModel{
+		Keys: bson.D{{Key: "host", Value: 1}},
+		Options: options.Index().
+			SetName("host_verified").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"verified": true}),
+	})
+	dropIndex(domainsCollection, "host_1")
+
+	initShortCodeGenerator()
+	reloadPolicyRules()
+	reloadVerifiedDomains()
+}
+
+// dropIndex removes an index that has been replaced, it is fine for it to
+// be gone already
+func dropIndex(collection *mongo.Collection, name string) {
+	_, err := collection.Indexes().DropOne(context.Background(), name)
+	// 26 is NamespaceNotFound and 27 IndexNotFound
+	var commandErr mongo.CommandError
+	if err != nil && !(errors.As(err, &commandErr) && (commandErr.Code == 27 || commandErr.Code == 26)) {
+		log.Printf("Failed to drop index %s on %s: %v", name, collection.Name(), err)
+	}
+}
+
+func createIndex(collection *mongo.Collection, index mongo.IndexModel) {
+	_, err := collection.Indexes().CreateOne(context.Background(), index)
+	if err != nil {
+		log.Printf("Failed to create index on %s: %v", collection.Name(), err)
+	}
 }
@@ -0,0 +1,20 @@
+package controllers
+
+import "time"
+
+// StartBackgroundJobs starts the periodic tasks the api relies on
+func StartBackgroundJobs() {
+	// Other instances may have changed the rules and verified domains
+	go runEvery(policyRefreshInterval, reloadPolicyRules)
+	go runEvery(domainRefreshInterval, reloadVerifiedDomains)
+	go runEvery(trashPurgeInterval, purgeTrash)
+}
+
+func runEvery(interval time.Duration, job func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		job()
+	}
+}
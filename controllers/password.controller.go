@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/manlikehenryy/url-shortener-go/configs"
+	"github.com/manlikehenryy/url-shortener-go/database"
+	"github.com/manlikehenryy/url-shortener-go/helpers"
+	"github.com/manlikehenryy/url-shortener-go/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	unlockCookieTTL     = time.Hour
+	maxUnlockAttempts   = 5
+	unlockAttemptWindow = 15 * time.Minute
+
+	minLinkPasswordLength = 4
+	maxLinkPasswordLength = 72 // bcrypt ignores anything longer
+)
+
+// VisitMethods are the methods short links answer to, so a 307 or 308
+// redirect can keep the visitor's method
+var VisitMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// VisitURL handles a request to a short link. A POST can be a password
+// posted from a protected link's password page.
+func VisitURL(c *gin.Context) {
+	if c.Request.Method == http.MethodPost {
+		UnlockURL(c)
+		return
+	}
+	RedirectURL(c)
+}
+
+// UnlockURL checks the password posted from a protected link's password
+// page and remembers a correct one with a short-lived cookie. A POST to any
+// other link is a visit.
+func UnlockURL(c *gin.Context) {
+	shortURL := c.Param("shortURL")
+
+	filter := codeFilter(requestDomain(c), shortURL)
+	filter["deletedAt"] = nil
+
+	var url models.Url
+	err := urlCollection.FindOne(context.Background(), filter).Decode(&url)
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.Println("Database error:", err)
+		helpers.SendMessagePage(c, http.StatusInternalServerError, "Something went wrong", "Please try again later.")
+		return
+	}
+
+	// Other links are visited with the POST, so a 307 or 308 can pass it on,
+	// and unknown ones get the same fallback as a GET
+	if err == mongo.ErrNoDocuments || !url.Protected {
+		RedirectURL(c)
+		return
+	}
+
+	// Attempts have their own limit below
+	helpers.NotAVisit(c)
+
+	attemptsKey := fmt.Sprintf("unlock_attempts:%s:%s", helpers.GetClientIP(c), urlRedisKey(&url))
+	// Every attempt is counted before the password is compared, so parallel
+	// requests can't all get in under the limit
+	attempts, err := database.RDB.Incr(ctx, attemptsKey).Result()
+	if err != nil {
+		log.Println("Redis error:", err)
+	} else if attempts == 1 {
+		database.RDB.Expire(ctx, attemptsKey, unlockAttemptWindow)
+	}
+
+	if attempts > maxUnlockAttempts {
+		sendPasswordPage(c, &url, http.StatusTooManyRequests, "Too many incorrect attempts, try again later")
+		return
+	}
+
+	if err := url.ComparePassword(c.PostForm("password")); err != nil {
+		sendPasswordPage(c, &url, http.StatusUnauthorized, "Incorrect password")
+		return
+	}
+
+	database.RDB.Del(ctx, attemptsKey)
+
+	token, err := helpers.GenerateUnlockToken(shortURL, url.PasswordHash, unlockCookieTTL)
+	if err != nil {
+		log.Println("Token generation error:", err)
+		helpers.SendMessagePage(c, http.StatusInternalServerError, "Something went wrong", "Please try again later.")
+		return
+	}
+
+	maxAge := int(unlockCookieTTL / time.Second)
+	c.SetCookie(unlockCookieName(shortURL), token, maxAge, "/"+shortURL, "", configs.Env.MODE == "production", true)
+
+	c.Redirect(http.StatusSeeOther, linkPath(c, shortURL))
+}
+
+// linkPath is the short link's path with the query string of the current
+// request, so it survives the password page for query passthrough
+func linkPath(c *gin.Context, shortURL string) string {
+	if c.Request.URL.RawQuery == "" {
+		return "/" + shortURL
+	}
+	return "/" + shortURL + "?" + c.Request.URL.RawQuery
+}
+
+func unlockCookieName(shortURL string) string {
+	return "unlock_" + shortURL
+}
+
+// isUnlocked reports whether the visitor has already entered the password
+func isUnlocked(c *gin.Context, url *models.Url) bool {
+	token, err := c.Cookie(unlockCookieName(url.ShortUrl))
+	if err != nil {
+		return false
+	}
+	return helpers.ValidUnlockToken(token, url.ShortUrl, url.PasswordHash)
+}
+
+func sendPasswordPage(c *gin.Context, url *models.Url, statusCode int, message string) {
+	helpers.NotAVisit(c)
+	helpers.RenderPage(c, statusCode, "password", gin.H{
+		"Title":  "Password required",
+		"Action": linkPath(c, url.ShortUrl),
+		"Error":  message,
+	})
+}
+
+func validateLinkPassword(password *string) string {
+	if password == nil || *password == "" {
+		return ""
+	}
+
+	if len(*password) < minLinkPasswordLength || len(*password) > maxLinkPasswordLength {
+		return fmt.Sprintf("must be between %d and %d characters", minLinkPasswordLength, maxLinkPasswordLength)
+	}
+	return ""
+}
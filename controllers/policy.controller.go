@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/manlikehenryy/url-shortener-go/helpers"
+	"github.com/manlikehenryy/url-shortener-go/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const policyRefreshInterval = time.Minute
+
+// destinationPolicy caches the policy rules so destinations can be checked
+// on every redirect without a database round trip
+var destinationPolicy = &helpers.PolicyEngine{}
+
+// loadPolicyRules refreshes the cached rules from the database
+func loadPolicyRules() error {
+	cursor, err := policiesCollection.Find(context.Background(), bson.M{})
+	if err != nil {
+		return err
+	}
+
+	var rules []models.PolicyRule
+	if err := cursor.All(context.Background(), &rules); err != nil {
+		return err
+	}
+
+	destinationPolicy.Load(rules)
+	return nil
+}
+
+func reloadPolicyRules() {
+	if err := loadPolicyRules(); err != nil {
+		log.Println("Failed to load policy rules:", err)
+	}
+}
+
+func GetAllPolicyRules(c *gin.Context) {
+	cursor, err := policiesCollection.Find(
+		context.Background(),
+		bson.M{},
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}),
+	)
+	if err != nil {
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to retrieve policy rules")
+		return
+	}
+
+	rules := []models.PolicyRule{}
+	if err := cursor.All(context.Background(), &rules); err != nil {
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to retrieve policy rules")
+		return
+	}
+
+	helpers.SendJSON(c, http.StatusOK, gin.H{
+		"data":    rules,
+		"message": "Data fetched successfully",
+	})
+}
+
+func CreatePolicyRule(c *gin.Context) {
+	var rule models.PolicyRule
+	if !bindPolicyRule(c, &rule) {
+		return
+	}
+
+	userId, ok := c.MustGet("userId").(primitive.ObjectID)
+	if !ok {
+		helpers.SendError(c, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	rule.CreatedBy = userId
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = time.Now()
+
+	insertResult, err := policiesCollection.InsertOne(context.Background(), rule)
+	if err != nil {
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to create policy rule")
+		return
+	}
+
+	rule.ID = insertResult.InsertedID.(primitive.ObjectID)
+	reloadPolicyRules()
+
+	helpers.SendJSON(c, http.StatusCreated, gin.H{
+		"data":    rule,
+		"message": "Policy rule created successfully",
+	})
+}
+
+func UpdatePolicyRule(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		helpers.SendError(c, http.StatusBadRequest, "Invalid policy rule ID")
+		return
+	}
+
+	var rule models.PolicyRule
+	if !bindPolicyRule(c, &rule) {
+		return
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"action":    rule.Action,
+			"match":     rule.Match,
+			"pattern":   rule.Pattern,
+			"note":      rule.Note,
+			"updatedAt": time.Now(),
+		},
+	}
+
+	result, err := policiesCollection.UpdateOne(context.Background(), bson.M{"_id": id}, update)
+	if err != nil {
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to update policy rule")
+		return
+	}
+
+	if result.MatchedCount == 0 {
+		helpers.SendError(c, http.StatusNotFound, "Policy rule not found")
+		return
+	}
+
+	reloadPolicyRules()
+
+	helpers.SendJSON(c, http.StatusOK, gin.H{
+		"message": "Policy rule updated successfully",
+	})
+}
+
+func DeletePolicyRule(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		helpers.SendError(c, http.StatusBadRequest, "Invalid policy rule ID")
+		return
+	}
+
+	result, err := policiesCollection.DeleteOne(context.Background(), bson.M{"_id": id})
+	if err != nil {
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to delete policy rule")
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		helpers.SendError(c, http.StatusNotFound, "Policy rule not found")
+		return
+	}
+
+	reloadPolicyRules()
+
+	helpers.SendJSON(c, http.StatusOK, gin.H{
+		"message": "Policy rule deleted successfully",
+	})
+}
+
+func bindPolicyRule(c *gin.Context, rule *models.PolicyRule) bool {
+	if err := c.ShouldBindJSON(rule); err != nil {
+		log.Println("Unable to parse body:", err)
+		if fieldErrors, ok := helpers.BindingFieldErrors(err); ok {
+			helpers.SendValidationError(c, fieldErrors)
+		} else {
+			helpers.SendError(c, http.StatusBadRequest, "Invalid request body")
+		}
+		return false
+	}
+
+	if err := helpers.NormalizePolicyRule(rule); err != nil {
+		helpers.SendValidationError(c, helpers.FieldErrors{"pattern": err.Error()})
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/manlikehenryy/url-shortener-go/configs"
+	"github.com/manlikehenryy/url-shortener-go/helpers"
+	"github.com/manlikehenryy/url-shortener-go/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	previewSuffix    = "+"
+	previewCookieTTL = time.Hour
+	maxTitleLength   = 200
+)
+
+// PreviewURL shows where a short link goes without following it or
+// counting a click. It serves /preview/:shortURL and, through
+// RedirectURL, the code followed by a +.
+func PreviewURL(c *gin.Context) {
+	shortURL := strings.TrimSuffix(c.Param("shortURL"), previewSuffix)
+
+	filter := codeFilter(requestDomain(c), shortURL)
+	filter["deletedAt"] = nil
+
+	var url models.Url
+	err := urlCollection.FindOne(context.Background(), filter).Decode(&url)
+	if err == mongo.ErrNoDocuments {
+		helpers.SendErrorPage(c, http.StatusNotFound, "Link not found", "This link doesn't exist or has expired.")
+		return
+	} else if err != nil {
+		log.Println("Database error:", err)
+		helpers.SendErrorPage(c, http.StatusInternalServerError, "Something went wrong", "Please try again later.")
+		return
+	}
+
+	switch url.ComputeStatus(time.Now()) {
+	case models.UrlStatusDisabled:
+		helpers.SendErrorPage(c, http.StatusGone, "Link disabled", "This link has been disabled by its owner.")
+		return
+	case models.UrlStatusExpired, models.UrlStatusExhausted:
+		helpers.SendErrorPage(c, http.StatusGone, "Link expired", "This link is no longer available.")
+		return
+	case models.UrlStatusScheduled:
+		// Same as visiting the link, the destination stays hidden until activeFrom
+		if configs.Env.NOT_YET_AVAILABLE_URL != "" {
+			c.Redirect(http.StatusFound, configs.Env.NOT_YET_AVAILABLE_URL)
+			return
+		}
+		helpers.SendErrorPage(c, http.StatusForbidden, "Link not yet available", "This link is not yet available.")
+		return
+	}
+
+	if destinationPolicy.Check(url.OriginalUrl) != nil {
+		helpers.SendErrorPage(c, http.StatusForbidden, "Link blocked", "This link has been blocked")
+		return
+	}
+
+	sendPreviewPage(c, &url)
+}
+
+// sendPreviewPage shows the preview and remembers that the visitor has
+// seen it, so the Continue button gets past a forced preview
+func sendPreviewPage(c *gin.Context, url *models.Url) {
+	helpers.NotAVisit(c)
+
+	// The destination of a protected link stays hidden until it is unlocked
+	destination := url.OriginalUrl
+	if url.Protected && !isUnlocked(c, url) {
+		destination = ""
+	}
+
+	if helpers.WantsJSON(c) {
+		helpers.SendJSON(c, http.StatusOK, gin.H{
+			"data": gin.H{
+				"shortUrl":    fullShortURL(url.Domain, url.ShortUrl),
+				"originalUrl": destination,
+				"title":       url.Title,
+				"protected":   url.Protected,
+				"createdAt":   url.CreatedAt,
+			},
+		})
+		return
+	}
+
+	maxAge := int(previewCookieTTL / time.Second)
+	c.SetCookie(previewCookieName(url.ShortUrl), "1", maxAge, "/"+url.ShortUrl, "", configs.Env.MODE == "production", true)
+
+	helpers.RenderPage(c, http.StatusOK, "preview", gin.H{
+		"Title":       "Link preview",
+		"LinkTitle":   url.Title,
+		"Destination": destination,
+		"CreatedAt":   url.CreatedAt,
+		"Continue":    linkPath(c, url.ShortUrl),
+	})
+}
+
+func previewCookieName(shortURL string) string {
+	return "preview_" + shortURL
+}
+
+// needsPreview reports whether a visitor has to see the preview page before
+// being redirected
+func needsPreview(c *gin.Context, url *models.Url) bool {
+	if !url.ForcePreview {
+		return false
+	}
+	_, err := c.Cookie(previewCookieName(url.ShortUrl))
+	return err != nil
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/manlikehenryy/url-shortener-go/configs"
+	"github.com/manlikehenryy/url-shortener-go/helpers"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const (
+	defaultQRSize   = 256
+	minQRSize       = 64
+	maxQRSize       = 2048
+	defaultQRMargin = 4
+	maxQRMargin     = 16
+)
+
+var (
+	qrLogo     *helpers.QRLogo
+	qrLogoErr  error
+	qrLogoOnce sync.Once
+)
+
+// loadQRLogo reads QR_LOGO_PATH the first time a logo is asked for
+func loadQRLogo() (*helpers.QRLogo, error) {
+	qrLogoOnce.Do(func() {
+		if configs.Env.QR_LOGO_PATH == "" {
+			return
+		}
+		qrLogo, qrLogoErr = helpers.LoadQRLogo(configs.Env.QR_LOGO_PATH)
+		if qrLogoErr != nil {
+			log.Println("Failed to load QR logo:", qrLogoErr)
+		}
+	})
+	return qrLogo, qrLogoErr
+}
+
+// GetUrlQR returns a QR code for a url's short link as a PNG or SVG
+func GetUrlQR(c *gin.Context) {
+	url, ok := findOwnedUrl(c)
+	if !ok {
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", "png"))
+	options, fieldErrors := qrOptions(c)
+	if format != "png" && format != "svg" {
+		fieldErrors["format"] = "must be png or svg"
+	}
+	if len(fieldErrors) > 0 {
+		helpers.SendValidationError(c, fieldErrors)
+		return
+	}
+
+	if c.Query("logo") == "true" {
+		logo, err := loadQRLogo()
+		if err != nil {
+			helpers.SendError(c, http.StatusInternalServerError, "Failed to load QR logo")
+			return
+		}
+		if logo == nil {
+			helpers.SendError(c, http.StatusBadRequest, "No QR logo is configured")
+			return
+		}
+		options.Logo = logo
+	}
+
+	content := absoluteShortURL(url.Domain, url.ShortUrl)
+
+	var data []byte
+	var err error
+	contentType := "image/png"
+	if format == "svg" {
+		data, err = helpers.RenderQRSVG(content, options)
+		contentType = "image/svg+xml"
+	} else {
+		data, err = helpers.RenderQRPNG(content, options)
+	}
+	var tooSmall *helpers.QRTooSmallError
+	if errors.As(err, &tooSmall) {
+		helpers.SendValidationError(c, helpers.FieldErrors{"size": tooSmall.Error()})
+		return
+	} else if err != nil {
+		log.Println("QR code error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to generate QR code")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.%s"`, url.ShortUrl, format))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// qrOptions reads the drawing options from the query string
+func qrOptions(c *gin.Context) (helpers.QROptions, helpers.FieldErrors) {
+	fieldErrors := helpers.FieldErrors{}
+	options := helpers.QROptions{
+		Size:       defaultQRSize,
+		Margin:     defaultQRMargin,
+		Level:      qrcode.Medium,
+		Foreground: color.RGBA{A: 0xff},
+		Background: color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	}
+
+	if size := c.Query("size"); size != "" {
+		value, err := strconv.Atoi(size)
+		if err != nil || value < minQRSize || value > maxQRSize {
+			fieldErrors["size"] = fmt.Sprintf("must be between %d and %d", minQRSize, maxQRSize)
+		}
+		options.Size = value
+	}
+
+	if margin := c.Query("margin"); margin != "" {
+		value, err := strconv.Atoi(margin)
+		if err != nil || value < 0 || value > maxQRMargin {
+			fieldErrors["margin"] = fmt.Sprintf("must be between 0 and %d", maxQRMargin)
+		}
+		options.Margin = value
+	}
+
+	// A logo hides part of the code, so it needs the highest level unless
+	// one is chosen
+	if level := c.Query("level"); level != "" {
+		recoveryLevel, err := helpers.ParseQRLevel(level)
+		if err != nil {
+			fieldErrors["level"] = err.Error()
+		}
+		options.Level = recoveryLevel
+	} else if c.Query("logo") == "true" {
+		options.Level = qrcode.Highest
+	}
+
+	for name, target := range map[string]*color.RGBA{"fg": &options.Foreground, "bg": &options.Background} {
+		if value := c.Query(name); value != "" {
+			parsed, err := helpers.ParseHexColor(value)
+			if err != nil {
+				fieldErrors[name] = err.Error()
+			}
+			*target = parsed
+		}
+	}
+
+	return options, fieldErrors
+}
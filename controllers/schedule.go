@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	_ "time/tzdata" // schedule rules need time zones on hosts without zoneinfo
+
+	"github.com/manlikehenryy/url-shortener-go/helpers"
+	"github.com/manlikehenryy/url-shortener-go/models"
+)
+
+const maxScheduleRules = 20
+
+// validateScheduleRules checks and normalizes a url's schedule rules, day
+// names may be written out in full
+func validateScheduleRules(url *models.Url) helpers.FieldErrors {
+	fieldErrors := helpers.FieldErrors{}
+	if len(url.ScheduleRules) == 0 {
+		url.ScheduleRules = nil
+		return fieldErrors
+	}
+
+	if len(url.ScheduleRules) > maxScheduleRules {
+		fieldErrors["scheduleRules"] = fmt.Sprintf("must have at most %d rules", maxScheduleRules)
+		return fieldErrors
+	}
+
+	for i := range url.ScheduleRules {
+		rule := &url.ScheduleRules[i]
+		field := fmt.Sprintf("scheduleRules.%d", i)
+
+		days := []string{}
+		for _, day := range rule.Days {
+			day = shortWeekday(day)
+			if day == "" {
+				fieldErrors[field+".days"] = "must be days of the week, e.g. mon or monday"
+				break
+			}
+			if !slices.Contains(days, day) {
+				days = append(days, day)
+			}
+		}
+		rule.Days = days
+
+		if (rule.StartTime == "") != (rule.EndTime == "") {
+			fieldErrors[field+".startTime"] = "startTime and endTime must be set together"
+		} else if rule.StartTime != "" {
+			start, errStart := time.Parse("15:04", rule.StartTime)
+			end, errEnd := time.Parse("15:04", rule.EndTime)
+			switch {
+			case errStart != nil:
+				fieldErrors[field+".startTime"] = "must be a time such as 09:00"
+			case errEnd != nil:
+				fieldErrors[field+".endTime"] = "must be a time such as 17:30"
+			case start.Equal(end):
+				fieldErrors[field+".endTime"] = "must be different from startTime"
+			default:
+				rule.StartTime = start.Format("15:04")
+				rule.EndTime = end.Format("15:04")
+			}
+		}
+
+		for name, value := range map[string]string{"startDate": rule.StartDate, "endDate": rule.EndDate} {
+			if value == "" {
+				continue
+			}
+			if _, err := time.Parse("2006-01-02", value); err != nil {
+				fieldErrors[field+"."+name] = "must be a date such as 2025-12-24"
+			}
+		}
+		if rule.StartDate != "" && rule.EndDate != "" && rule.EndDate < rule.StartDate {
+			fieldErrors[field+".endDate"] = "must not be before startDate"
+		}
+
+		if rule.TimeZone != "" {
+			if _, err := time.LoadLocation(rule.TimeZone); err != nil {
+				fieldErrors[field+".timeZone"] = "must be a time zone such as Europe/London"
+			}
+		}
+
+		destination, err := checkDestination(rule.Destination)
+		if err != nil {
+			fieldErrors[field+".destination"] = err.Error()
+		} else {
+			rule.Destination = destination
+		}
+	}
+
+	return fieldErrors
+}
+
+// shortWeekday turns a day such as "mon" or "Monday" into the name rules
+// are stored with, "" when it isn't a day of the week
+func shortWeekday(day string) string {
+	day = strings.ToLower(strings.TrimSpace(day))
+	for i, short := range models.Weekdays {
+		if day == short || day == strings.ToLower(time.Weekday(i).String()) {
+			return short
+		}
+	}
+	return ""
+}
+
+// scheduledDestination is the destination of the first rule that applies now
+func scheduledDestination(url *models.Url, now time.Time) (string, bool) {
+	for i := range url.ScheduleRules {
+		if url.ScheduleRules[i].Matches(now) {
+			return url.ScheduleRules[i].Destination, true
+		}
+	}
+	return "", false
+}
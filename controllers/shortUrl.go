@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/manlikehenryy/url-shortener-go/configs"
+	"github.com/manlikehenryy/url-shortener-go/database"
+	"github.com/manlikehenryy/url-shortener-go/helpers"
+	"github.com/manlikehenryy/url-shortener-go/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	shortCodeCounterKey  = "short_code:counter"
+	maxShortCodeAttempts = 5
+)
+
+var errShortUrlTaken = errors.New("short url is already taken")
+
+var shortCodeGenerator helpers.ShortCodeGenerator
+
+func initShortCodeGenerator() {
+	length := 0
+	if configs.Env.SHORT_CODE_LENGTH != "" {
+		var err error
+		length, err = strconv.Atoi(configs.Env.SHORT_CODE_LENGTH)
+		if err != nil {
+			log.Fatalf("Invalid SHORT_CODE_LENGTH: %v", err)
+		}
+	}
+
+	generator, err := helpers.NewShortCodeGenerator(
+		configs.Env.SHORT_CODE_STRATEGY,
+		length,
+		configs.Env.SHORT_CODE_SALT,
+		func() (int64, error) {
+			return database.RDB.Incr(ctx, shortCodeCounterKey).Result()
+		},
+	)
+	if err != nil {
+		log.Fatalf("Failed to set up short code generator: %v", err)
+	}
+
+	shortCodeGenerator = generator
+}
+
+// redisKey is the Redis key holding a code's destination. Codes on the
+// shortener's own domain use the bare code, branded domains prefix it with
+// the host so the same code can exist on each.
+func redisKey(domain string, shortURL string) string {
+	if domain == "" {
+		return shortURL
+	}
+	return domain + "/" + shortURL
+}
+
+// urlRedisKey is redisKey for a stored url
+func urlRedisKey(url *models.Url) string {
+	return redisKey(url.Domain, url.ShortUrl)
+}
+
+// codeFilter matches the url with a code on a domain, urls on the
+// shortener's own domain have no domain field
+func codeFilter(domain string, shortURL string) bson.M {
+	if domain == "" {
+		return bson.M{"shortUrl": shortURL, "domain": nil}
+	}
+	return bson.M{"shortUrl": shortURL, "domain": domain}
+}
+
+// isShortUrlTaken reports whether a code is already used on a domain by a
+// stored url (expired or not) or by a live Redis key
+func isShortUrlTaken(domain string, shortURL string) (bool, error) {
+	count, err := urlCollection.CountDocuments(context.Background(), codeFilter(domain, shortURL))
+	if err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	exists, err := database.RDB.Exists(ctx, redisKey(domain, shortURL)).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return exists > 0, nil
+}
+
+// claimShortURL stores the Redis entry for a code, failing with
+// errShortUrlTaken instead of overwriting a code that is already in use
+func claimShortURL(domain string, shortURL string, originalURL string, expiration time.Duration) error {
+	taken, err := isShortUrlTaken(domain, shortURL)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return errShortUrlTaken
+	}
+
+	stored, err := database.RDB.SetNX(ctx, redisKey(domain, shortURL), originalURL, expiration).Result()
+	if err != nil {
+		return err
+	}
+	if !stored {
+		return errShortUrlTaken
+	}
+
+	return nil
+}
+
+// reserveShortURL claims the alias if one is given, otherwise it generates
+// codes until one is free
+func reserveShortURL(domain string, alias string, originalURL string, expiration time.Duration) (string, error) {
+	if alias != "" {
+		return alias, claimShortURL(domain, alias, originalURL, expiration)
+	}
+
+	for attempt := 0; attempt < maxShortCodeAttempts; attempt++ {
+		shortURL, err := shortCodeGenerator.Generate(originalURL)
+		if err != nil {
+			return "", err
+		}
+
+		err = claimShortURL(domain, shortURL, originalURL, expiration)
+		if err == errShortUrlTaken {
+			log.Println("Short url collision, retrying:", shortURL)
+			continue
+		}
+		return shortURL, err
+	}
+
+	return "", errors.New("failed to generate a unique short url")
+}
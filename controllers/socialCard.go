@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/manlikehenryy/url-shortener-go/helpers"
+	"github.com/manlikehenryy/url-shortener-go/models"
+)
+
+const (
+	maxCardTitleLength       = 200
+	maxCardDescriptionLength = 500
+)
+
+// validateSocialCard checks a url's social card, an empty card is dropped
+func validateSocialCard(url *models.Url) helpers.FieldErrors {
+	fieldErrors := helpers.FieldErrors{}
+	card := url.SocialCard
+	if card == nil {
+		return fieldErrors
+	}
+
+	card.Title = strings.TrimSpace(card.Title)
+	card.Description = strings.TrimSpace(card.Description)
+	card.ImageUrl = strings.TrimSpace(card.ImageUrl)
+
+	if *card == (models.SocialCard{}) {
+		url.SocialCard = nil
+		return fieldErrors
+	}
+
+	if len(card.Title) > maxCardTitleLength {
+		fieldErrors["socialCard.title"] = "must be at most 200 characters"
+	}
+	if len(card.Description) > maxCardDescriptionLength {
+		fieldErrors["socialCard.description"] = "must be at most 500 characters"
+	}
+
+	if card.ImageUrl != "" {
+		imageUrl, err := checkDestination(card.ImageUrl)
+		if err != nil {
+			fieldErrors["socialCard.imageUrl"] = err.Error()
+		} else {
+			card.ImageUrl = imageUrl
+		}
+	}
+
+	return fieldErrors
+}
+
+// sendSocialCard shows a link preview bot the url's card. Links without
+// one get a card with just their title, so a bot is never sent on as a
+// visitor and counted.
+func sendSocialCard(c *gin.Context, url *models.Url) {
+	card := models.SocialCard{}
+	if url.SocialCard != nil {
+		card = *url.SocialCard
+	}
+
+	link := absoluteShortURL(url.Domain, url.ShortUrl)
+
+	title := card.Title
+	if title == "" {
+		title = url.Title
+	}
+	if title == "" {
+		title = link
+	}
+
+	helpers.RenderPage(c, http.StatusOK, "card", gin.H{
+		"Title":       title,
+		"Description": card.Description,
+		"ImageUrl":    card.ImageUrl,
+		"Url":         link,
+	})
+}
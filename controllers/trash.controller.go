@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/manlikehenryy/url-shortener-go/configs"
+	"github.com/manlikehenryy/url-shortener-go/database"
+	"github.com/manlikehenryy/url-shortener-go/helpers"
+	"github.com/manlikehenryy/url-shortener-go/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultTrashRetentionDays = 30
+	trashPurgeInterval        = time.Hour
+)
+
+// trashRetention is how long deleted urls can still be restored
+func trashRetention() time.Duration {
+	days := defaultTrashRetentionDays
+	if configs.Env.TRASH_RETENTION_DAYS != "" {
+		if n, err := strconv.Atoi(configs.Env.TRASH_RETENTION_DAYS); err == nil && n >= 0 {
+			days = n
+		} else {
+			log.Println("Invalid TRASH_RETENTION_DAYS, using the default")
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func GetTrash(c *gin.Context) {
+	userId, ok := c.MustGet("userId").(primitive.ObjectID)
+	if !ok {
+		helpers.SendError(c, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	filter := bson.M{"userId": userId, "deletedAt": bson.M{"$ne": nil}}
+
+	var urls []models.Url
+	params, err := helpers.PaginateCollection(c, urlCollection, filter, &urls)
+	if err != nil {
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to retrieve urls")
+		return
+	}
+
+	now := time.Now()
+	for i := range urls {
+		urls[i].Status = urls[i].ComputeStatus(now)
+	}
+
+	helpers.SendPaginatedResponse(c, urls, params)
+}
+
+func RestoreUrl(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := primitive.ObjectIDFromHex(idStr)
+	if err != nil {
+		helpers.SendError(c, http.StatusBadRequest, "Invalid url ID")
+		return
+	}
+
+	userId, ok := c.MustGet("userId").(primitive.ObjectID)
+	if !ok {
+		helpers.SendError(c, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	update := bson.M{
+		"$unset": bson.M{"deletedAt": ""},
+		"$set":   bson.M{"updatedAt": time.Now()},
+	}
+
+	filter := bson.M{"_id": id, "userId": userId, "deletedAt": bson.M{"$ne": nil}}
+	result, err := urlCollection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to restore url")
+		return
+	}
+
+	if result.MatchedCount == 0 {
+		helpers.SendError(c, http.StatusNotFound, "Url not found in trash")
+		return
+	}
+
+	helpers.SendJSON(c, http.StatusOK, gin.H{
+		"message": "Url restored successfully",
+	})
+}
+
+// purgeTrash permanently deletes urls that have been in the trash longer
+// than the retention period, together with their Redis keys and history
+func purgeTrash() {
+	cutoff := time.Now().Add(-trashRetention())
+
+	purged := 0
+	for {
+		// One at a time with the cutoff in the filter, so a url restored
+		// while the purge runs is left alone and only the keys of urls that
+		// were actually removed are deleted
+		var url models.Url
+		err := urlCollection.FindOneAndDelete(
+			context.Background(),
+			bson.M{"deletedAt": bson.M{"$lte": cutoff}},
+			options.FindOneAndDelete().SetProjection(bson.M{"_id": 1, "shortUrl": 1, "domain": 1}),
+		).Decode(&url)
+		if err == mongo.ErrNoDocuments {
+			break
+		} else if err != nil {
+			log.Println("Failed to purge urls:", err)
+			break
+		}
+
+		key := urlRedisKey(&url)
+		if err := database.RDB.Del(ctx, key, clickCounterKey(key)).Err(); err != nil {
+			log.Println("Failed to delete purged url keys:", err)
+		}
+
+		if _, err := historyCollection.DeleteMany(context.Background(), bson.M{"urlId": url.ID}); err != nil {
+			log.Println("Failed to purge url history:", err)
+		}
+
+		purged++
+	}
+
+	if purged > 0 {
+		log.Printf("Purged %d urls from the trash", purged)
+	}
+}
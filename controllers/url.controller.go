@@ -2,9 +2,12 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -16,16 +19,247 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var ctx = context.Background()
 
+// bindUrlPayload binds a url from the request body and sends the error
+// response itself when the body is invalid
+func bindUrlPayload(c *gin.Context, url *models.Url) bool {
+	if err := c.ShouldBindJSON(url); err != nil {
+		log.Println("Unable to parse body:", err)
+		if fieldErrors, ok := helpers.BindingFieldErrors(err); ok {
+			helpers.SendValidationError(c, fieldErrors)
+		} else {
+			helpers.SendError(c, http.StatusBadRequest, "Invalid request body")
+		}
+		return false
+	}
+
+	return true
+}
+
+// validateUrlFields checks and normalizes the fields that can be set on
+// both create and update
+func validateUrlFields(url *models.Url) helpers.FieldErrors {
+	fieldErrors := helpers.FieldErrors{}
+
+	originalUrl, err := checkDestination(url.OriginalUrl)
+	if err != nil {
+		fieldErrors["originalUrl"] = err.Error()
+	} else {
+		url.OriginalUrl = originalUrl
+	}
+
+	for field, message := range validateDeepLinks(url) {
+		fieldErrors[field] = message
+	}
+
+	for field, message := range validateGeoTargets(url) {
+		fieldErrors[field] = message
+	}
+
+	for field, message := range validateVariants(url) {
+		fieldErrors[field] = message
+	}
+
+	for field, message := range validateScheduleRules(url) {
+		fieldErrors[field] = message
+	}
+
+	for field, message := range validateSocialCard(url) {
+		fieldErrors[field] = message
+	}
+
+	url.Title = strings.TrimSpace(url.Title)
+	if len(url.Title) > maxTitleLength {
+		fieldErrors["title"] = "must be at most 200 characters"
+	}
+
+	if url.FallbackUrl != "" {
+		fallbackUrl, err := checkDestination(url.FallbackUrl)
+		if err != nil {
+			fieldErrors["fallbackUrl"] = err.Error()
+		} else {
+			url.FallbackUrl = fallbackUrl
+		}
+	}
+
+	if url.MaxClicks < 0 {
+		fieldErrors["maxClicks"] = "must not be negative"
+	}
+
+	if url.RedirectType == 0 {
+		url.RedirectType = http.StatusFound
+	} else if !slices.Contains(models.RedirectTypes, url.RedirectType) {
+		fieldErrors["redirectType"] = "must be one of 301, 302, 307 or 308"
+	}
+
+	for field, message := range helpers.ValidateQueryOptions(url) {
+		fieldErrors[field] = message
+	}
+
+	if message := validateLinkPassword(url.Password); message != "" {
+		fieldErrors["password"] = message
+	}
+
+	for field, message := range helpers.ResolveExpiration(url, time.Now()) {
+		fieldErrors[field] = message
+	}
+
+	if url.ActiveFrom != nil && url.ExpiresAt != nil && !url.ActiveFrom.Before(*url.ExpiresAt) {
+		fieldErrors["activeFrom"] = "must be before the link expires"
+	}
+
+	return fieldErrors
+}
+
+// checkDestination normalizes a url visitors can be sent to and checks it
+// against the destination policy
+func checkDestination(raw string) (string, error) {
+	destination, err := helpers.NormalizeDestination(raw, isShortenerHost)
+	if err != nil {
+		return "", err
+	}
+	if destinationPolicy.Check(destination) != nil {
+		return "", errors.New("destination is not allowed by the link policy")
+	}
+	return destination, nil
+}
+
+// checkRedirectLoop follows the destination's redirects when
+// CHECK_REDIRECT_LOOPS is on. It makes network requests, so it is only run
+// for urls saved one at a time, not for bulk creates and imports.
+func checkRedirectLoop(url *models.Url) helpers.FieldErrors {
+	if configs.Env.CHECK_REDIRECT_LOOPS != "true" {
+		return nil
+	}
+	if err := helpers.DetectRedirectLoop(url.OriginalUrl, isShortenerHost); err != nil {
+		return helpers.FieldErrors{"originalUrl": err.Error()}
+	}
+	return nil
+}
+
+// validateNewUrl runs the checks a url payload needs on top of its binding
+// tags, the returned error is a helpers.FieldErrors
+func validateNewUrl(url *models.Url) error {
+	fieldErrors := validateUrlFields(url)
+
+	if url.Alias != "" {
+		if err := helpers.ValidateAlias(url.Alias); err != nil {
+			fieldErrors["alias"] = err.Error()
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return fieldErrors
+	}
+	return nil
+}
+
+// prepareNewUrl fills in the fields every new url document starts with
+func prepareNewUrl(url *models.Url, shortURL string) {
+	url.ShortUrl = shortURL
+	url.Alias = ""
+	url.Dedupe = nil
+	url.ExhaustedAt = nil
+	url.DeletedAt = nil
+	url.RemovePassword()
+	if url.Password != nil && *url.Password != "" {
+		url.SetPassword(*url.Password)
+	}
+	url.Password = nil
+	url.ClickDetails = []models.Click{}
+	url.Version = 1
+	url.CreatedAt = time.Now()
+	url.UpdatedAt = time.Now()
+	url.Status = url.ComputeStatus(time.Now())
+}
+
+// insertNewUrl claims a code for a prepared url and saves it. A generated
+// code can still be in Mongo when its Redis entry is gone, e.g. after Redis
+// lost its data, so another one is tried. errShortUrlTaken is only returned
+// for an alias.
+func insertNewUrl(url *models.Url, alias string) error {
+	for attempt := 0; attempt < maxShortCodeAttempts; attempt++ {
+		// Store the original URL in Redis with expiration, without overwriting an existing code
+		shortURL, err := reserveShortURL(url.Domain, alias, url.OriginalUrl, redisTTL(url))
+		if err != nil {
+			return err
+		}
+		url.ShortUrl = shortURL
+
+		insertResult, err := urlCollection.InsertOne(context.Background(), url)
+		if err == nil {
+			url.ID = insertResult.InsertedID.(primitive.ObjectID)
+			return nil
+		}
+
+		// Release the code so it isn't left pointing at a url that was never saved
+		database.RDB.Del(ctx, redisKey(url.Domain, shortURL))
+		if !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+		if alias != "" {
+			return errShortUrlTaken
+		}
+		log.Println("Short url collision, retrying:", shortURL)
+	}
+
+	return errors.New("failed to generate a unique short url")
+}
+
+// redisTTL is how long a url's Redis entry should live, 0 means no expiry.
+// It is worked out from expiresAt so Redis and Mongo agree on the expiry.
+func redisTTL(url *models.Url) time.Duration {
+	if url.ExpiresAt == nil {
+		return 0
+	}
+
+	ttl := time.Until(*url.ExpiresAt)
+	if ttl < time.Second {
+		// Redis treats 0 as no expiry, keep an already due url short lived
+		ttl = time.Second
+	}
+	return ttl
+}
+
+// isUrlActive reports whether a url can still be visited
+func isUrlActive(url *models.Url) (bool, error) {
+	if url.DeletedAt != nil || url.Disabled || url.ExhaustedAt != nil || url.IsScheduled(time.Now()) {
+		return false, nil
+	}
+
+	exists, err := database.RDB.Exists(ctx, urlRedisKey(url)).Result()
+	if err != nil {
+		return false, err
+	}
+	return exists > 0, nil
+}
+
+// fullShortURL is the address handed back to users for a code on a domain
+func fullShortURL(domain string, shortURL string) string {
+	if domain != "" {
+		return fmt.Sprintf("%s/%s", domain, shortURL)
+	}
+	return fmt.Sprintf("%s/%s", configs.Env.APP_URL, shortURL)
+}
+
+// absoluteShortURL is fullShortURL with a scheme, for scanners and link
+// preview bots that only follow absolute links
+func absoluteShortURL(domain string, shortURL string) string {
+	link := fullShortURL(domain, shortURL)
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+	return link
+}
+
 func CreateUrl(c *gin.Context) {
 	var url models.Url
 
-	if err := c.ShouldBindJSON(&url); err != nil {
-		log.Println("Unable to parse body:", err)
-		helpers.SendError(c, http.StatusBadRequest, "Invalid request body")
+	if !bindUrlPayload(c, &url) {
 		return
 	}
 
@@ -37,31 +271,64 @@ func CreateUrl(c *gin.Context) {
 
 	url.UserId = userId
 
-	shortURL := helpers.GenerateShortURL(url.OriginalUrl)
-	expiration := time.Duration(url.Expiration) * time.Second
+	if err := validateNewUrl(&url); err != nil {
+		helpers.SendValidationError(c, err)
+		return
+	}
 
-	// Store the original URL in Redis with expiration
-	err := database.RDB.Set(ctx, shortURL, url.OriginalUrl, expiration).Err()
-	if err != nil {
-		log.Println(err)
-		helpers.SendError(c, http.StatusInternalServerError, "Failed to store URL")
+	if fieldErrors := checkRedirectLoop(&url); len(fieldErrors) > 0 {
+		helpers.SendValidationError(c, fieldErrors)
 		return
 	}
 
-	url.ShortUrl = shortURL
-	url.ClickDetails = []models.Click{}
-	url.CreatedAt = time.Now()
-	url.UpdatedAt = time.Now() 
+	domain, err := urlDomain(userId, url.Domain)
+	if err != nil {
+		helpers.SendValidationError(c, helpers.FieldErrors{"domain": err.Error()})
+		return
+	}
+	url.Domain = domain
 
-	insertResult, err := urlCollection.InsertOne(context.Background(), url)
+	dedupe, err := shouldDedupe(&url)
 	if err != nil {
 		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to retrieve account")
+		return
+	}
+
+	if dedupe {
+		existingUrl, err := findActiveDuplicate(userId, url.Domain, url.OriginalUrl)
+		if err != nil {
+			log.Println("Database error:", err)
+			helpers.SendError(c, http.StatusInternalServerError, "Failed to check existing urls")
+			return
+		}
+
+		if existingUrl != nil {
+			existingUrl.ShortUrl = fullShortURL(existingUrl.Domain, existingUrl.ShortUrl)
+			existingUrl.Status = existingUrl.ComputeStatus(time.Now())
+			helpers.SendJSON(c, http.StatusOK, gin.H{
+				"data":    existingUrl,
+				"message": "Existing url returned",
+			})
+			return
+		}
+	}
+
+	alias := url.Alias
+	prepareNewUrl(&url, "")
+
+	err = insertNewUrl(&url, alias)
+	if err == errShortUrlTaken {
+		helpers.SendError(c, http.StatusConflict, "Alias is already taken")
+		return
+	} else if err != nil {
+		log.Println(err)
 		helpers.SendError(c, http.StatusInternalServerError, "Failed to create url")
 		return
 	}
 
-	url.ID = insertResult.InsertedID.(primitive.ObjectID)
-	url.ShortUrl = fmt.Sprintf("%s/%s", configs.Env.APP_URL, shortURL)
+	recordRevision(newRevision(&url, nil, userId, models.RevisionActionCreate))
+	url.ShortUrl = fullShortURL(url.Domain, url.ShortUrl)
 
 	helpers.SendJSON(c, http.StatusCreated, gin.H{
 		"data":    url,
@@ -72,39 +339,142 @@ func CreateUrl(c *gin.Context) {
 func RedirectURL(c *gin.Context) {
 	shortURL := c.Param("shortURL")
 
+	if strings.HasSuffix(shortURL, previewSuffix) {
+		PreviewURL(c)
+		return
+	}
+
+	// Codes are scoped to the domain the link was visited on
+	domain := requestDomain(c)
+
 	// Fetch the original URL from Redis
-	originalURL, err := database.RDB.Get(ctx, shortURL).Result()
+	originalURL, err := database.RDB.Get(ctx, redisKey(domain, shortURL)).Result()
 	if err == redis.Nil {
-		helpers.SendError(c, http.StatusNotFound, "URL not found or expired")
+		sendMissingLink(c, domain, shortURL)
 		return
 	} else if err != nil {
 		helpers.SendError(c, http.StatusInternalServerError, "Failed to retrieve URL")
 		return
 	}
 
-	// Update click count and append user details
-	url, err := urlCollection.UpdateOne(
-		context.TODO(),
-		bson.M{"shortUrl": shortURL},
-		bson.M{
-			"$inc": bson.M{"clickCount": 1},
-			"$push": bson.M{"clickDetails": bson.M{
-				"ipAddress": helpers.GetClientIP(c),
-				"timestamp": time.Now(),
-			}},
-		},
-	)
+	// Rules may have been added since the link was created
+	if destinationPolicy.Check(originalURL) != nil {
+		helpers.SendErrorPage(c, http.StatusForbidden, "Link blocked", "This link has been blocked")
+		return
+	}
 
-	if err != nil {
+	filter := codeFilter(domain, shortURL)
+	filter["deletedAt"] = nil
+
+	var url models.Url
+	err = urlCollection.FindOne(context.Background(), filter).Decode(&url)
+	if err == mongo.ErrNoDocuments {
+		sendUnavailableLink(c, nil, domain, http.StatusNotFound, "URL not found or expired",
+			"Link not found", "This link doesn't exist. Check that it was copied correctly.")
+		return
+	} else if err != nil {
 		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to retrieve URL")
+		return
+	}
+
+	if url.Disabled {
+		helpers.SendErrorPage(c, http.StatusGone, "Link disabled", "This link has been disabled by its owner.")
+		return
+	}
+
+	// Checked before the counter, which can't be trusted once the limit is
+	// recorded, e.g. after the link's key has been rewritten
+	if url.ExhaustedAt != nil {
+		sendUnavailableLink(c, &url, domain, http.StatusGone, "This link has reached its click limit",
+			"Link expired", "This link has reached its click limit and is no longer available.")
+		return
+	}
+
+	if url.IsScheduled(time.Now()) {
+		if configs.Env.NOT_YET_AVAILABLE_URL != "" {
+			c.Redirect(http.StatusFound, configs.Env.NOT_YET_AVAILABLE_URL)
+			return
+		}
+		helpers.SendErrorPage(c, http.StatusForbidden, "Link not yet available", "This link is not yet available.")
+		return
 	}
 
-	if url.MatchedCount == 0 {
-		log.Println("Url not found")
+	// Bots building a link preview get a card and are not counted
+	if helpers.IsSocialCrawler(c.Request.UserAgent()) {
+		sendSocialCard(c, &url)
+		return
+	}
+
+	if url.Protected && !isUnlocked(c, &url) {
+		sendPasswordPage(c, &url, http.StatusUnauthorized, "")
+		return
+	}
+
+	if needsPreview(c, &url) {
+		sendPreviewPage(c, &url)
+		return
+	}
+
+	set := bson.M{}
+
+	// Counted last so a click the visitor never gets through doesn't use up the limit
+	if url.MaxClicks > 0 {
+		count, ok, err := consumeClick(&url)
+		if err != nil {
+			log.Println("Redis error:", err)
+			helpers.SendError(c, http.StatusInternalServerError, "Failed to retrieve URL")
+			return
+		}
+
+		if !ok {
+			if err := markExhausted(&url); err != nil {
+				log.Println("Database error:", err)
+			}
+			sendUnavailableLink(c, &url, domain, http.StatusGone, "This link has reached its click limit",
+				"Link expired", "This link has reached its click limit and is no longer available.")
+			return
+		}
+
+		if count == url.MaxClicks {
+			set["exhaustedAt"] = time.Now()
+		}
+	}
+
+	if url.ExpirationType == models.ExpirationSliding && url.Expiration > 0 {
+		ttl := time.Duration(url.Expiration) * time.Second
+		if err := database.RDB.Expire(ctx, urlRedisKey(&url), ttl).Err(); err != nil {
+			log.Println("Failed to extend expiration:", err)
+		} else {
+			set["expiresAt"] = time.Now().Add(ttl)
+		}
+	}
+
+	visit := planVisit(c, &url, originalURL)
+
+	// Update click count and append user details
+	click := bson.M{
+		"ipAddress": helpers.GetClientIP(c),
+		"timestamp": time.Now(),
+	}
+	if visit.variant != "" {
+		click["variant"] = visit.variant
+	}
+	update := bson.M{
+		"$inc":  bson.M{"clickCount": 1},
+		"$push": bson.M{"clickDetails": click},
+	}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+
+	_, err = urlCollection.UpdateOne(context.Background(), bson.M{"_id": url.ID}, update)
+	if err != nil {
+		log.Println("Database error:", err)
 	}
 
 	// Redirect to the original URL
-	c.Redirect(http.StatusFound, originalURL)
+	sendVisit(c, &url, visit)
 }
 
 
@@ -134,6 +504,8 @@ func GetUrl(c *gin.Context) {
 		return
 	}
 
+	url.Status = url.ComputeStatus(time.Now())
+
 	helpers.SendJSON(c, http.StatusOK, gin.H{
 		"data": url,
 	})
@@ -155,13 +527,22 @@ func UpdateUrl(c *gin.Context) {
 	}
 
 	var url models.Url
-	if err := c.ShouldBindJSON(&url); err != nil {
-		helpers.SendError(c, http.StatusBadRequest, "Invalid request body")
+	if !bindUrlPayload(c, &url) {
+		return
+	}
+
+	if fieldErrors := validateUrlFields(&url); len(fieldErrors) > 0 {
+		helpers.SendValidationError(c, fieldErrors)
+		return
+	}
+
+	if fieldErrors := checkRedirectLoop(&url); len(fieldErrors) > 0 {
+		helpers.SendValidationError(c, fieldErrors)
 		return
 	}
 
 	var existingUrl models.Url
-	err = urlCollection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&existingUrl)
+	err = urlCollection.FindOne(context.Background(), bson.M{"_id": id, "deletedAt": nil}).Decode(&existingUrl)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			helpers.SendError(c, http.StatusNotFound, "Url not found")
@@ -176,8 +557,29 @@ func UpdateUrl(c *gin.Context) {
 		return
 	}
 
-	expiration := time.Duration(url.Expiration) * time.Second
-	err = database.RDB.Set(ctx, existingUrl.ShortUrl, url.OriginalUrl, expiration).Err()
+	url.ShortUrl = existingUrl.ShortUrl
+	url.Domain = existingUrl.Domain
+
+	// Leaving the password out keeps the current one, an empty one removes it
+	switch {
+	case url.Password == nil:
+		url.PasswordHash = existingUrl.PasswordHash
+		url.Protected = existingUrl.Protected
+	case *url.Password == "":
+		url.RemovePassword()
+	default:
+		url.SetPassword(*url.Password)
+	}
+	url.Password = nil
+
+	exhaustedAt, err := exhaustedAtFor(&url, existingUrl.ExhaustedAt)
+	if err != nil {
+		log.Println(err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to update URL")
+		return
+	}
+
+	err = database.RDB.Set(ctx, urlRedisKey(&existingUrl), url.OriginalUrl, redisTTL(&url)).Err()
 	if err != nil {
 		log.Println(err)
 		helpers.SendError(c, http.StatusInternalServerError, "Failed to update URL")
@@ -186,23 +588,50 @@ func UpdateUrl(c *gin.Context) {
 
 	update := bson.M{
 		"$set": bson.M{
-			"originalUrl": url.OriginalUrl,
-			"expiration":  url.Expiration,
-			"updatedAt": time.Now(),
+			"originalUrl":      url.OriginalUrl,
+			"expiration":       url.Expiration,
+			"expirationType":   url.ExpirationType,
+			"expiresAt":        url.ExpiresAt,
+			"activeFrom":       url.ActiveFrom,
+			"maxClicks":        url.MaxClicks,
+			"redirectType":     url.RedirectType,
+			"queryPassthrough": url.QueryPassthrough,
+			"utm":              url.Utm,
+			"deepLinks":        url.DeepLinks,
+			"geoTargets":       url.GeoTargets,
+			"variants":         url.Variants,
+			"stickyVariants":   url.StickyVariants,
+			"scheduleRules":    url.ScheduleRules,
+			"title":            url.Title,
+			"forcePreview":     url.ForcePreview,
+			"socialCard":       url.SocialCard,
+			"fallbackUrl":      url.FallbackUrl,
+			"exhaustedAt":      exhaustedAt,
+			"passwordHash":     url.PasswordHash,
+			"protected":        url.Protected,
+			"updatedAt":        time.Now(),
 		},
 	}
 
-	result, err := urlCollection.UpdateOne(context.Background(), bson.M{"_id": id, "userId": userId}, update)
-	if err != nil {
+	update["$inc"] = bson.M{"version": 1}
+
+	var updatedUrl models.Url
+	err = urlCollection.FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": id, "userId": userId},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updatedUrl)
+	if err == mongo.ErrNoDocuments {
+		helpers.SendError(c, http.StatusNotFound, "Url not found or unauthorized")
+		return
+	} else if err != nil {
 		log.Println("Database error:", err)
 		helpers.SendError(c, http.StatusInternalServerError, "Failed to update url")
 		return
 	}
 
-	if result.MatchedCount == 0 {
-		helpers.SendError(c, http.StatusNotFound, "Url not found or unauthorized")
-		return
-	}
+	recordRevision(newRevision(&updatedUrl, &existingUrl, userId, models.RevisionActionUpdate))
 
 	helpers.SendJSON(c, http.StatusOK, gin.H{
 		"message": "Url updated successfully",
@@ -216,7 +645,7 @@ func GetAllUrl(c *gin.Context) {
 		return
 	}
 
-	filter := bson.M{"userId": userId}
+	filter := bson.M{"userId": userId, "deletedAt": nil}
 
 	var urls []models.Url
 	params, err := helpers.PaginateCollection(c, urlCollection, filter, &urls)
@@ -225,6 +654,11 @@ func GetAllUrl(c *gin.Context) {
 		return
 	}
 
+	now := time.Now()
+	for i := range urls {
+		urls[i].Status = urls[i].ComputeStatus(now)
+	}
+
 	helpers.SendPaginatedResponse(c, urls, params)
 }
 
@@ -259,25 +693,86 @@ func DeleteUrl(c *gin.Context) {
 		return
 	}
 
-	filter := bson.M{"_id": id, "userId": userId}
-	result, err := urlCollection.DeleteOne(context.Background(), filter)
+	if existingUrl.DeletedAt != nil {
+		helpers.SendError(c, http.StatusNotFound, "Url not found")
+		return
+	}
+
+	// Moved to the trash, the code and Redis entry are kept until the url is
+	// restored or purged
+	update := bson.M{
+		"$set": bson.M{
+			"deletedAt": time.Now(),
+			"updatedAt": time.Now(),
+		},
+	}
+
+	filter := bson.M{"_id": id, "userId": userId, "deletedAt": nil}
+	result, err := urlCollection.UpdateOne(context.Background(), filter, update)
 	if err != nil {
 		helpers.SendError(c, http.StatusInternalServerError, "Failed to delete url")
 		return
 	}
 
-	if result.DeletedCount == 0 {
+	if result.MatchedCount == 0 {
 		helpers.SendError(c, http.StatusNotFound, "Url not found or unauthorized")
 		return
 	}
 
-	err = database.RDB.Del(ctx, existingUrl.ShortUrl).Err()
+	helpers.SendJSON(c, http.StatusOK, gin.H{
+		"message": "Url moved to trash",
+	})
+}
+
+func DisableUrl(c *gin.Context) {
+	setUrlDisabled(c, true)
+}
+
+func EnableUrl(c *gin.Context) {
+	setUrlDisabled(c, false)
+}
+
+// setUrlDisabled pauses or resumes a url. The Redis entry and click history
+// are kept, so the code stays reserved while the url is disabled.
+func setUrlDisabled(c *gin.Context, disabled bool) {
+	idStr := c.Param("id")
+	id, err := primitive.ObjectIDFromHex(idStr)
+	if err != nil {
+		helpers.SendError(c, http.StatusBadRequest, "Invalid url ID")
+		return
+	}
+
+	userId, ok := c.MustGet("userId").(primitive.ObjectID)
+	if !ok {
+		helpers.SendError(c, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"disabled":  disabled,
+			"updatedAt": time.Now(),
+		},
+	}
+
+	result, err := urlCollection.UpdateOne(context.Background(), bson.M{"_id": id, "userId": userId, "deletedAt": nil}, update)
 	if err != nil {
-		helpers.SendError(c, http.StatusInternalServerError, "Failed to delete URL")
+		log.Println("Database error:", err)
+		helpers.SendError(c, http.StatusInternalServerError, "Failed to update url")
 		return
 	}
 
+	if result.MatchedCount == 0 {
+		helpers.SendError(c, http.StatusNotFound, "Url not found or unauthorized")
+		return
+	}
+
+	message := "Url enabled successfully"
+	if disabled {
+		message = "Url disabled successfully"
+	}
+
 	helpers.SendJSON(c, http.StatusOK, gin.H{
-		"message": "Url deleted successfully",
+		"message": message,
 	})
 }
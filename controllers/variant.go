@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/manlikehenryy/url-shortener-go/configs"
+	"github.com/manlikehenryy/url-shortener-go/helpers"
+	"github.com/manlikehenryy/url-shortener-go/models"
+)
+
+const (
+	maxVariants      = 10
+	maxVariantWeight = 1000
+	variantCookieTTL = 30 * 24 * time.Hour
+)
+
+var variantNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// validateVariants checks a url's A/B destinations, unnamed variants are
+// named A, B, C and so on
+func validateVariants(url *models.Url) helpers.FieldErrors {
+	fieldErrors := helpers.FieldErrors{}
+	if len(url.Variants) == 0 {
+		url.Variants = nil
+		url.StickyVariants = false
+		return fieldErrors
+	}
+
+	if len(url.Variants) > maxVariants {
+		fieldErrors["variants"] = fmt.Sprintf("must have at most %d variants", maxVariants)
+		return fieldErrors
+	}
+
+	names := map[string]bool{}
+	for i := range url.Variants {
+		variant := &url.Variants[i]
+		field := fmt.Sprintf("variants.%d", i)
+
+		if variant.Name == "" {
+			variant.Name = string(rune('A' + i))
+		}
+		if !variantNamePattern.MatchString(variant.Name) {
+			fieldErrors[field+".name"] = "must be 1-32 letters, numbers, hyphens or underscores"
+		} else if names[variant.Name] {
+			fieldErrors[field+".name"] = "must be unique"
+		}
+		names[variant.Name] = true
+
+		destination, err := checkDestination(variant.Url)
+		if err != nil {
+			fieldErrors[field+".url"] = err.Error()
+		} else {
+			variant.Url = destination
+		}
+
+		if variant.Weight < 1 || variant.Weight > maxVariantWeight {
+			fieldErrors[field+".weight"] = fmt.Sprintf("must be between 1 and %d", maxVariantWeight)
+		}
+	}
+
+	return fieldErrors
+}
+
+// chooseVariant picks a variant by weight, sticky urls keep showing a
+// visitor the variant they got first
+func chooseVariant(c *gin.Context, url *models.Url) *models.Variant {
+	if len(url.Variants) == 0 {
+		return nil
+	}
+
+	cookieName := "variant_" + url.ShortUrl
+	if url.StickyVariants {
+		if name, err := c.Cookie(cookieName); err == nil {
+			for i := range url.Variants {
+				if url.Variants[i].Name == name {
+					return &url.Variants[i]
+				}
+			}
+		}
+	}
+
+	total := 0
+	for _, variant := range url.Variants {
+		total += variant.Weight
+	}
+
+	pick := rand.IntN(total)
+	chosen := &url.Variants[len(url.Variants)-1]
+	for i := range url.Variants {
+		if pick < url.Variants[i].Weight {
+			chosen = &url.Variants[i]
+			break
+		}
+		pick -= url.Variants[i].Weight
+	}
+
+	if url.StickyVariants {
+		maxAge := int(variantCookieTTL / time.Second)
+		c.SetCookie(cookieName, chosen.Name, maxAge, "/"+url.ShortUrl, "", configs.Env.MODE == "production", true)
+	}
+
+	return chosen
+}
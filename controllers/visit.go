@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/manlikehenryy/url-shortener-go/helpers"
+	"github.com/manlikehenryy/url-shortener-go/models"
+)
+
+// visit is where a visitor is sent. It is worked out before the click is
+// recorded so the click can note the variant that was served.
+type visit struct {
+	destination string // the web page, or the app store when there is one
+	appUrl      string // tried first when set, destination is the fallback
+	variant     string
+}
+
+// planVisit picks the destination for the visitor's platform and country.
+// App store links come first, then schedule rules, geo targets, the
+// desktop page, A/B variants and finally originalUrl. UTM and query parameters are added to
+// web pages but not to app or store urls.
+func planVisit(c *gin.Context, url *models.Url, originalURL string) visit {
+	platform := helpers.DetectPlatform(c.Request.UserAgent())
+
+	link := url.DeepLinks.ForPlatform(platform)
+	if link != nil && link.StoreUrl != "" {
+		return visit{destination: link.StoreUrl, appUrl: link.AppUrl}
+	}
+
+	v := visit{destination: originalURL}
+	if link != nil {
+		v.appUrl = link.AppUrl
+	}
+
+	if scheduled, ok := scheduledDestination(url, time.Now()); ok {
+		v.destination = scheduled
+	} else if geoDestination, ok := geoTarget(c, url); ok {
+		v.destination = geoDestination
+	} else if platform == models.PlatformDesktop && url.DeepLinks != nil && url.DeepLinks.Desktop != "" {
+		v.destination = url.DeepLinks.Desktop
+	} else if variant := chooseVariant(c, url); variant != nil {
+		v.destination = variant.Url
+		v.variant = variant.Name
+	}
+
+	v.destination = helpers.BuildDestination(v.destination, url, c.Request.URL.Query())
+	return v
+}
+
+// sendVisit redirects the visitor, or shows the page that tries to open
+// the app first. The destination planVisit picked may not be originalUrl,
+// so it is checked against the current policy rules as well, along with
+// an app url that opens in the browser.
+func sendVisit(c *gin.Context, url *models.Url, v visit) {
+	if destinationPolicy.Check(v.destination) != nil || (helpers.IsWebURL(v.appUrl) && destinationPolicy.Check(v.appUrl) != nil) {
+		helpers.SendErrorPage(c, http.StatusForbidden, "Link blocked", "This link has been blocked")
+		return
+	}
+
+	if v.appUrl == "" {
+		c.Redirect(url.RedirectStatus(), v.destination)
+		return
+	}
+
+	helpers.RenderPage(c, http.StatusOK, "deeplink", gin.H{
+		"Title": "Opening the app",
+		// Validated on save, custom schemes would otherwise be filtered out
+		"AppUrl":      template.URL(v.appUrl),
+		"FallbackUrl": v.destination,
+	})
+}
@@ -0,0 +1,38 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	MinAliasLength = 3
+	MaxAliasLength = 32
+)
+
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Aliases that would clash with the app's own routes
+var reservedAliases = map[string]bool{
+	"api":     true,
+	"preview": true,
+}
+
+// ValidateAlias checks that a custom alias is usable as a short url code
+func ValidateAlias(alias string) error {
+	if len(alias) < MinAliasLength || len(alias) > MaxAliasLength {
+		return fmt.Errorf("Alias must be between %d and %d characters", MinAliasLength, MaxAliasLength)
+	}
+
+	if !aliasPattern.MatchString(alias) {
+		return errors.New("Alias may only contain letters, numbers, hyphens and underscores")
+	}
+
+	if reservedAliases[strings.ToLower(alias)] {
+		return errors.New("Alias is reserved")
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package helpers
+
+import "strings"
+
+// User-Agent fragments of the bots that fetch links to build previews in
+// social networks and chat apps
+var socialCrawlers = []string{
+	"facebookexternalhit",
+	"facebot",
+	"twitterbot",
+	"linkedinbot",
+	"slackbot",
+	"discordbot",
+	"telegrambot",
+	"whatsapp",
+	"skypeuripreview",
+	"pinterest",
+	"redditbot",
+	"vkshare",
+	"embedly",
+	"iframely",
+	"mastodon",
+	"bluesky",
+	"cardyb",
+	"snapchat",
+	"viber",
+	"line-poker",
+	"applebot",
+}
+
+// IsSocialCrawler reports whether a request comes from a link preview bot
+func IsSocialCrawler(userAgent string) bool {
+	ua := strings.ToLower(userAgent)
+	for _, crawler := range socialCrawlers {
+		if strings.Contains(ua, crawler) {
+			return true
+		}
+	}
+	return false
+}
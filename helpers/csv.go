@@ -0,0 +1,51 @@
+package helpers
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ReadCSVRecords reads a CSV file with a header row into one map per row,
+// keyed by the lower-cased header names
+func ReadCSVRecords(r io.Reader) ([]map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i, name := range header {
+		// Spreadsheet exports often start with a byte order mark
+		name = strings.TrimPrefix(name, "\ufeff")
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+
+	var records []map[string]string
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		record := make(map[string]string, len(header))
+		for i, value := range row {
+			if i < len(header) {
+				record[header[i]] = strings.TrimSpace(value)
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,62 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/manlikehenryy/url-shortener-go/models"
+)
+
+// Schemes that run code or read local data in the browser can never be
+// used to open an app
+var blockedAppSchemes = []string{"javascript", "data", "vbscript", "file", "blob", "about"}
+
+const maxAppURLLength = 2048
+
+// DetectPlatform works out the visitor's platform from a User-Agent
+// header. iPadOS reports itself as macOS by default, those visitors are
+// treated as desktop.
+func DetectPlatform(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ipod"):
+		return models.PlatformIOS
+	case strings.Contains(ua, "android"):
+		return models.PlatformAndroid
+	default:
+		return models.PlatformDesktop
+	}
+}
+
+// ValidateAppURL checks a url used to open an app, it can use any scheme
+// the app registered apart from those that run in the browser
+func ValidateAppURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if len(raw) > maxAppURLLength {
+		return "", fmt.Errorf("must be at most %d characters", maxAppURLLength)
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Scheme == "" {
+		return "", errors.New("must be a URL with a scheme, e.g. myapp://path")
+	}
+
+	if slices.Contains(blockedAppSchemes, strings.ToLower(parsed.Scheme)) {
+		return "", fmt.Errorf("scheme %q is not allowed", parsed.Scheme)
+	}
+
+	return raw, nil
+}
+
+// IsWebURL reports whether a url uses http or https, such as an app url
+// that is a universal link
+func IsWebURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return isAllowedScheme(strings.ToLower(parsed.Scheme))
+}
@@ -0,0 +1,96 @@
+package helpers
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/manlikehenryy/url-shortener-go/models"
+)
+
+const maxUtmLength = 200
+
+// ValidateQueryOptions checks and normalizes a url's query passthrough mode
+// and UTM parameters
+func ValidateQueryOptions(link *models.Url) FieldErrors {
+	fieldErrors := FieldErrors{}
+
+	switch link.QueryPassthrough {
+	case "":
+		link.QueryPassthrough = models.QueryPassthroughOff
+	case models.QueryPassthroughOff, models.QueryPassthroughKeep, models.QueryPassthroughOverride, models.QueryPassthroughAppend:
+	default:
+		fieldErrors["queryPassthrough"] = "must be one of off, keep, override or append"
+	}
+
+	if link.Utm == nil {
+		return fieldErrors
+	}
+
+	empty := true
+	for name, value := range utmFields(link.Utm) {
+		*value = strings.TrimSpace(*value)
+		if len(*value) > maxUtmLength {
+			fieldErrors["utm."+name] = "must be at most 200 characters"
+		}
+		if *value != "" {
+			empty = false
+		}
+	}
+	if empty {
+		link.Utm = nil
+	}
+
+	return fieldErrors
+}
+
+// BuildDestination assembles the url a visitor is redirected to from the
+// stored destination, the link's UTM parameters and, depending on the
+// link's passthrough mode, the query string the short link was visited with
+func BuildDestination(destination string, link *models.Url, incoming url.Values) string {
+	passthrough := link.QueryPassthrough != "" && link.QueryPassthrough != models.QueryPassthroughOff && len(incoming) > 0
+	if link.Utm == nil && !passthrough {
+		return destination
+	}
+
+	target, err := url.Parse(destination)
+	if err != nil {
+		return destination
+	}
+	query := target.Query()
+
+	if link.Utm != nil {
+		for name, value := range utmFields(link.Utm) {
+			if *value != "" {
+				query.Set("utm_"+name, *value)
+			}
+		}
+	}
+
+	if passthrough {
+		for key, values := range incoming {
+			switch link.QueryPassthrough {
+			case models.QueryPassthroughKeep:
+				if !query.Has(key) {
+					query[key] = values
+				}
+			case models.QueryPassthroughOverride:
+				query[key] = values
+			case models.QueryPassthroughAppend:
+				query[key] = append(query[key], values...)
+			}
+		}
+	}
+
+	target.RawQuery = query.Encode()
+	return target.String()
+}
+
+func utmFields(utm *models.UtmParams) map[string]*string {
+	return map[string]*string{
+		"source":   &utm.Source,
+		"medium":   &utm.Medium,
+		"campaign": &utm.Campaign,
+		"term":     &utm.Term,
+		"content":  &utm.Content,
+	}
+}
@@ -0,0 +1,77 @@
+package helpers
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/manlikehenryy/url-shortener-go/models"
+)
+
+func TestBuildDestination(t *testing.T) {
+	incoming := url.Values{"b": {"9"}, "ref": {"x"}}
+	utm := &models.UtmParams{Source: "news", Campaign: "fall"}
+
+	tests := []struct {
+		name        string
+		destination string
+		link        models.Url
+		incoming    url.Values
+		want        string
+	}{
+		{
+			name:        "nothing to add",
+			destination: "https://example.com/a?b=2&a=1",
+			link:        models.Url{QueryPassthrough: models.QueryPassthroughOff},
+			incoming:    incoming,
+			want:        "https://example.com/a?b=2&a=1",
+		},
+		{
+			name:        "utm parameters",
+			destination: "https://example.com/a?utm_source=old",
+			link:        models.Url{Utm: utm},
+			want:        "https://example.com/a?utm_campaign=fall&utm_source=news",
+		},
+		{
+			name:        "keep passthrough",
+			destination: "https://example.com/a?b=2",
+			link:        models.Url{QueryPassthrough: models.QueryPassthroughKeep},
+			incoming:    incoming,
+			want:        "https://example.com/a?b=2&ref=x",
+		},
+		{
+			name:        "override passthrough",
+			destination: "https://example.com/a?b=2",
+			link:        models.Url{QueryPassthrough: models.QueryPassthroughOverride},
+			incoming:    incoming,
+			want:        "https://example.com/a?b=9&ref=x",
+		},
+		{
+			name:        "append passthrough",
+			destination: "https://example.com/a?b=2",
+			link:        models.Url{QueryPassthrough: models.QueryPassthroughAppend},
+			incoming:    incoming,
+			want:        "https://example.com/a?b=2&b=9&ref=x",
+		},
+		{
+			name:        "passthrough without a query string",
+			destination: "https://example.com/a#top",
+			link:        models.Url{QueryPassthrough: models.QueryPassthroughKeep},
+			want:        "https://example.com/a#top",
+		},
+		{
+			name:        "utm and passthrough",
+			destination: "https://example.com/a#top",
+			link:        models.Url{QueryPassthrough: models.QueryPassthroughOverride, Utm: utm},
+			incoming:    url.Values{"utm_source": {"visitor"}},
+			want:        "https://example.com/a?utm_campaign=fall&utm_source=visitor#top",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildDestination(tt.destination, &tt.link, tt.incoming); got != tt.want {
+				t.Errorf("BuildDestination() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package helpers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/idna"
+)
+
+const (
+	domainVerificationPrefix = "_urlshortener."
+	domainVerificationValue  = "url-shortener-verification="
+)
+
+// TXTResolver looks up DNS TXT records. net.DefaultResolver is used in
+// production, tests can replace DomainResolver with a fake.
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+var DomainResolver TXTResolver = net.DefaultResolver
+
+// NormalizeDomain checks a domain name a user wants to serve links from and
+// returns it lower-case and in punycode
+func NormalizeDomain(domain string) (string, error) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if domain == "" {
+		return "", errors.New("is required")
+	}
+
+	if strings.ContainsAny(domain, ":/?#@") || net.ParseIP(domain) != nil {
+		return "", errors.New("must be a domain name such as go.example.com, without a scheme, port or path")
+	}
+
+	ascii, err := idna.Registration.ToASCII(domain)
+	if err != nil || !strings.Contains(ascii, ".") {
+		return "", errors.New("must be a domain name such as go.example.com")
+	}
+
+	return ascii, nil
+}
+
+// NewDomainVerificationToken is the random value a user publishes to prove
+// they own a domain
+func NewDomainVerificationToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// DomainVerificationRecord is the TXT record name and value that prove
+// ownership of a domain
+func DomainVerificationRecord(domain string, token string) (string, string) {
+	return domainVerificationPrefix + domain, domainVerificationValue + token
+}
+
+// VerifyDomain reports whether the domain's verification TXT record holds
+// the token
+func VerifyDomain(ctx context.Context, resolver TXTResolver, domain string, token string) (bool, error) {
+	name, value := DomainVerificationRecord(domain, token)
+
+	records, err := resolver.LookupTXT(ctx, name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	for _, record := range records {
+		if strings.TrimSpace(record) == value {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HostSet is a set of hosts that is safe to read while it is reloaded
+type HostSet struct {
+	mu    sync.RWMutex
+	hosts map[string]bool
+}
+
+// Load replaces the hosts in the set
+func (s *HostSet) Load(hosts []string) {
+	loaded := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		loaded[host] = true
+	}
+
+	s.mu.Lock()
+	s.hosts = loaded
+	s.mu.Unlock()
+}
+
+// Has reports whether host, without a port, is in the set
+func (s *HostSet) Has(host string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.hosts[host]
+}
@@ -0,0 +1,98 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+type fakeResolver struct {
+	records map[string][]string
+	err     error
+}
+
+func (r fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	records, ok := r.records[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func TestNormalizeDomain(t *testing.T) {
+	valid := map[string]string{
+		"go.example.com":     "go.example.com",
+		" Go.Example.COM. ":  "go.example.com",
+		"bücher.example.com": "xn--bcher-kva.example.com",
+	}
+	for input, want := range valid {
+		got, err := NormalizeDomain(input)
+		if err != nil {
+			t.Errorf("NormalizeDomain(%q) returned error: %v", input, err)
+		} else if got != want {
+			t.Errorf("NormalizeDomain(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"localhost",
+		"https://go.example.com",
+		"go.example.com:8080",
+		"go.example.com/path",
+		"user@go.example.com",
+		"127.0.0.1",
+		"go..example.com",
+	}
+	for _, input := range invalid {
+		if got, err := NormalizeDomain(input); err == nil {
+			t.Errorf("NormalizeDomain(%q) = %q, want an error", input, got)
+		}
+	}
+}
+
+func TestVerifyDomain(t *testing.T) {
+	name, value := DomainVerificationRecord("go.example.com", "token")
+
+	tests := []struct {
+		name     string
+		resolver fakeResolver
+		want     bool
+		wantErr  bool
+	}{
+		{
+			name:     "record matches",
+			resolver: fakeResolver{records: map[string][]string{name: {"v=spf1 -all", " " + value + " "}}},
+			want:     true,
+		},
+		{
+			name:     "record has another token",
+			resolver: fakeResolver{records: map[string][]string{name: {"url-shortener-verification=other"}}},
+		},
+		{
+			name:     "record is missing",
+			resolver: fakeResolver{},
+		},
+		{
+			name:     "lookup fails",
+			resolver: fakeResolver{err: errors.New("server misbehaving")},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyDomain(context.Background(), tt.resolver, "go.example.com", "token")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyDomain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("VerifyDomain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
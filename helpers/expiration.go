@@ -0,0 +1,50 @@
+package helpers
+
+import (
+	"time"
+
+	"github.com/manlikehenryy/url-shortener-go/models"
+)
+
+// ResolveExpiration validates a url's expiration settings and sets
+// ExpiresAt from now. Without an expirationType the type is worked out
+// from the other fields, so {"expiration": 240} keeps its old meaning and
+// an expiration of 0 never expires.
+func ResolveExpiration(url *models.Url, now time.Time) FieldErrors {
+	fieldErrors := FieldErrors{}
+
+	if url.ExpirationType == "" {
+		switch {
+		case url.ExpiresAt != nil:
+			url.ExpirationType = models.ExpirationAbsolute
+		case url.Expiration > 0:
+			url.ExpirationType = models.ExpirationDuration
+		default:
+			url.ExpirationType = models.ExpirationNever
+		}
+	}
+
+	switch url.ExpirationType {
+	case models.ExpirationDuration, models.ExpirationSliding:
+		if url.Expiration <= 0 {
+			fieldErrors["expiration"] = "must be a positive number of seconds"
+			break
+		}
+		expiresAt := now.Add(time.Duration(url.Expiration) * time.Second)
+		url.ExpiresAt = &expiresAt
+	case models.ExpirationAbsolute:
+		if url.ExpiresAt == nil {
+			fieldErrors["expiresAt"] = "is required"
+		} else if !url.ExpiresAt.After(now) {
+			fieldErrors["expiresAt"] = "must be in the future"
+		}
+		url.Expiration = 0
+	case models.ExpirationNever:
+		url.Expiration = 0
+		url.ExpiresAt = nil
+	default:
+		fieldErrors["expirationType"] = "must be duration, absolute, never or sliding"
+	}
+
+	return fieldErrors
+}
@@ -0,0 +1,106 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/manlikehenryy/url-shortener-go/models"
+)
+
+func TestResolveExpiration(t *testing.T) {
+	now := time.Date(2024, 10, 18, 12, 0, 0, 0, time.UTC)
+	later := now.Add(48 * time.Hour)
+	earlier := now.Add(-time.Hour)
+	plus := func(d time.Duration) *time.Time {
+		at := now.Add(d)
+		return &at
+	}
+
+	tests := []struct {
+		name          string
+		url           models.Url
+		wantType      string
+		wantSeconds   int64
+		wantExpiresAt *time.Time
+		wantError     string // field with an error, "" for none
+	}{
+		{
+			name:     "nothing set never expires",
+			url:      models.Url{},
+			wantType: models.ExpirationNever,
+		},
+		{
+			name:          "seconds imply a duration",
+			url:           models.Url{Expiration: 3600},
+			wantType:      models.ExpirationDuration,
+			wantSeconds:   3600,
+			wantExpiresAt: plus(time.Hour),
+		},
+		{
+			name:          "expiresAt implies absolute",
+			url:           models.Url{ExpiresAt: &later, Expiration: 60},
+			wantType:      models.ExpirationAbsolute,
+			wantExpiresAt: &later,
+		},
+		{
+			name:          "sliding starts from now",
+			url:           models.Url{ExpirationType: models.ExpirationSliding, Expiration: 600},
+			wantType:      models.ExpirationSliding,
+			wantSeconds:   600,
+			wantExpiresAt: plus(10 * time.Minute),
+		},
+		{
+			name:     "never clears the other fields",
+			url:      models.Url{ExpirationType: models.ExpirationNever, Expiration: 60, ExpiresAt: &later},
+			wantType: models.ExpirationNever,
+		},
+		{
+			name:      "duration needs seconds",
+			url:       models.Url{ExpirationType: models.ExpirationDuration},
+			wantError: "expiration",
+		},
+		{
+			name:      "absolute needs expiresAt",
+			url:       models.Url{ExpirationType: models.ExpirationAbsolute},
+			wantError: "expiresAt",
+		},
+		{
+			name:      "absolute must be in the future",
+			url:       models.Url{ExpiresAt: &earlier},
+			wantError: "expiresAt",
+		},
+		{
+			name:      "unknown type",
+			url:       models.Url{ExpirationType: "forever"},
+			wantError: "expirationType",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := tt.url
+			fieldErrors := ResolveExpiration(&url, now)
+
+			if tt.wantError == "" && len(fieldErrors) > 0 {
+				t.Fatalf("ResolveExpiration() errors = %v, want none", fieldErrors)
+			}
+			if _, ok := fieldErrors[tt.wantError]; tt.wantError != "" && !ok {
+				t.Fatalf("ResolveExpiration() errors = %v, want one for %s", fieldErrors, tt.wantError)
+			}
+			if tt.wantError != "" {
+				return
+			}
+
+			if url.ExpirationType != tt.wantType {
+				t.Errorf("expirationType = %q, want %q", url.ExpirationType, tt.wantType)
+			}
+			if url.Expiration != tt.wantSeconds {
+				t.Errorf("expiration = %d, want %d", url.Expiration, tt.wantSeconds)
+			}
+			if (url.ExpiresAt == nil) != (tt.wantExpiresAt == nil) ||
+				(url.ExpiresAt != nil && !url.ExpiresAt.Equal(*tt.wantExpiresAt)) {
+				t.Errorf("expiresAt = %v, want %v", url.ExpiresAt, tt.wantExpiresAt)
+			}
+		})
+	}
+}
@@ -1,14 +1,171 @@
 package helpers
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
-	"fmt"
-	"time"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
 )
 
-// Function to generate a short URL (hash of the original URL)
-func GenerateShortURL(originalURL string) string {
-	hash := sha256.Sum256([]byte(originalURL + fmt.Sprintf("%d", time.Now().UnixNano())))
-	return hex.EncodeToString(hash[:])[:8] // Return only the first 8 characters
-}
\ No newline at end of file
+const base62Alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+const (
+	DefaultShortCodeLength = 8
+	MinShortCodeLength     = 4
+	MaxShortCodeLength     = 32
+	// 62^10 is the largest power of 62 that fits in an int64
+	maxHashidLength = 10
+)
+
+// ShortCodeGenerator produces candidate short url codes. Codes are not
+// guaranteed to be unique, callers must check for collisions.
+type ShortCodeGenerator interface {
+	Generate(originalURL string) (string, error)
+}
+
+// CounterFunc returns the next value of a monotonically increasing counter
+type CounterFunc func() (int64, error)
+
+// NewShortCodeGenerator builds the generator for a strategy name: "random"
+// (default), "counter" or "hashid"
+func NewShortCodeGenerator(strategy string, length int, salt string, next CounterFunc) (ShortCodeGenerator, error) {
+	if length == 0 {
+		length = DefaultShortCodeLength
+	}
+	if length < MinShortCodeLength || length > MaxShortCodeLength {
+		return nil, errors.New("short code length must be between 4 and 32")
+	}
+
+	switch strings.ToLower(strategy) {
+	case "", "random":
+		return &RandomGenerator{Length: length}, nil
+	case "counter":
+		return &CounterGenerator{Length: length, Next: next}, nil
+	case "hashid":
+		if length > maxHashidLength {
+			return nil, errors.New("hashid short code length must be at most 10")
+		}
+		return NewHashidGenerator(length, salt, next), nil
+	default:
+		return nil, errors.New("unknown short code strategy: " + strategy)
+	}
+}
+
+// RandomGenerator returns random base62 codes of a fixed length
+type RandomGenerator struct {
+	Length int
+}
+
+func (g *RandomGenerator) Generate(originalURL string) (string, error) {
+	max := big.NewInt(int64(len(base62Alphabet)))
+	code := make([]byte, g.Length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = base62Alphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// CounterGenerator base62 encodes a shared counter, left padded to Length
+type CounterGenerator struct {
+	Length int
+	Next   CounterFunc
+}
+
+func (g *CounterGenerator) Generate(originalURL string) (string, error) {
+	if g.Next == nil {
+		return "", errors.New("counter generator has no counter")
+	}
+	n, err := g.Next()
+	if err != nil {
+		return "", err
+	}
+
+	code := encodeBase62(big.NewInt(n), base62Alphabet)
+	if len(code) < g.Length {
+		code = strings.Repeat(string(base62Alphabet[0]), g.Length-len(code)) + code
+	}
+	return code, nil
+}
+
+// HashidGenerator turns a shared counter into fixed length codes that don't
+// look sequential. The counter is scrambled with a multiplication that is a
+// bijection modulo 62^Length and encoded with a salt-shuffled alphabet, so
+// codes stay collision free until the code space is used up.
+type HashidGenerator struct {
+	Length   int
+	Next     CounterFunc
+	alphabet string
+	space    *big.Int
+}
+
+// Odd and not a multiple of 31, so it is coprime with every power of 62
+var hashidMultiplier = big.NewInt(1580030173)
+
+func NewHashidGenerator(length int, salt string, next CounterFunc) *HashidGenerator {
+	return &HashidGenerator{
+		Length:   length,
+		Next:     next,
+		alphabet: shuffleAlphabet(base62Alphabet, salt),
+		space:    new(big.Int).Exp(big.NewInt(62), big.NewInt(int64(length)), nil),
+	}
+}
+
+func (g *HashidGenerator) Generate(originalURL string) (string, error) {
+	if g.Next == nil {
+		return "", errors.New("hashid generator has no counter")
+	}
+	n, err := g.Next()
+	if err != nil {
+		return "", err
+	}
+
+	scrambled := new(big.Int).Mul(big.NewInt(n), hashidMultiplier)
+	scrambled.Mod(scrambled, g.space)
+
+	code := encodeBase62(scrambled, g.alphabet)
+	if len(code) < g.Length {
+		code = strings.Repeat(string(g.alphabet[0]), g.Length-len(code)) + code
+	}
+	return code, nil
+}
+
+func encodeBase62(n *big.Int, alphabet string) string {
+	if n.Sign() == 0 {
+		return string(alphabet[0])
+	}
+
+	base := big.NewInt(int64(len(alphabet)))
+	value := new(big.Int).Set(n)
+	mod := new(big.Int)
+	var out []byte
+	for value.Sign() > 0 {
+		value.DivMod(value, base, mod)
+		out = append(out, alphabet[mod.Int64()])
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// shuffleAlphabet is the consistent shuffle used by hashids
+func shuffleAlphabet(alphabet, salt string) string {
+	if salt == "" {
+		return alphabet
+	}
+
+	out := []byte(alphabet)
+	for i, v, p := len(out)-1, 0, 0; i > 0; i-- {
+		v %= len(salt)
+		p += int(salt[v])
+		j := (int(salt[v]) + v + p) % i
+		out[i], out[j] = out[j], out[i]
+		v++
+	}
+	return string(out)
+}
@@ -0,0 +1,66 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashidGeneratorIsABijection(t *testing.T) {
+	// Small enough to walk the whole code space
+	const length = 2
+	const space = 62 * 62
+
+	for _, salt := range []string{"", "pepper"} {
+		t.Run("salt "+salt, func(t *testing.T) {
+			var counter int64
+			generator := NewHashidGenerator(length, salt, func() (int64, error) {
+				n := counter
+				counter++
+				return n, nil
+			})
+
+			seen := make(map[string]int64, space)
+			for n := int64(0); n < space; n++ {
+				code, err := generator.Generate("https://example.com")
+				if err != nil {
+					t.Fatalf("Generate() returned error: %v", err)
+				}
+				if len(code) != length {
+					t.Fatalf("Generate() = %q for %d, want %d characters", code, n, length)
+				}
+				for _, char := range code {
+					if !strings.ContainsRune(base62Alphabet, char) {
+						t.Fatalf("Generate() = %q for %d, want base62 characters", code, n)
+					}
+				}
+				if previous, ok := seen[code]; ok {
+					t.Fatalf("Generate() = %q for both %d and %d", code, previous, n)
+				}
+				seen[code] = n
+			}
+		})
+	}
+}
+
+func TestHashidGeneratorSalt(t *testing.T) {
+	generate := func(salt string, n int64) string {
+		generator := NewHashidGenerator(DefaultShortCodeLength, salt, func() (int64, error) {
+			return n, nil
+		})
+		code, err := generator.Generate("https://example.com")
+		if err != nil {
+			t.Fatalf("Generate() returned error: %v", err)
+		}
+		return code
+	}
+
+	if generate("pepper", 1) != generate("pepper", 1) {
+		t.Error("the same salt and counter gave different codes")
+	}
+	if generate("pepper", 1) == generate("salt", 1) {
+		t.Error("different salts gave the same code")
+	}
+	if generate("pepper", 1) == generate("pepper", 2) {
+		t.Error("consecutive counters gave the same code")
+	}
+}
@@ -0,0 +1,57 @@
+package helpers
+
+import (
+	"log"
+	"net"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+var geoIP *maxminddb.Reader
+
+// OpenGeoIP loads a MaxMind country or city database. Without one, or when
+// it can't be read, visitors can't be located and geo targeting is skipped.
+func OpenGeoIP(path string) {
+	if path == "" {
+		return
+	}
+
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		log.Println("Failed to open GeoIP database:", err)
+		return
+	}
+	geoIP = reader
+}
+
+// CountryForIP is the ISO 3166-1 alpha-2 code of the country an address is
+// in, "" when it isn't known
+func CountryForIP(ip string) string {
+	if geoIP == nil {
+		return ""
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+
+	var record struct {
+		Country struct {
+			IsoCode string `maxminddb:"iso_code"`
+		} `maxminddb:"country"`
+		RegisteredCountry struct {
+			IsoCode string `maxminddb:"iso_code"`
+		} `maxminddb:"registered_country"`
+	}
+	if err := geoIP.Lookup(parsed, &record); err != nil {
+		log.Println("GeoIP lookup error:", err)
+		return ""
+	}
+
+	if record.Country.IsoCode != "" {
+		return strings.ToUpper(record.Country.IsoCode)
+	}
+	return strings.ToUpper(record.RegisteredCountry.IsoCode)
+}
@@ -0,0 +1,144 @@
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	ImportFormatBitlyCSV  = "bitly-csv"
+	ImportFormatBitlyJSON = "bitly-json"
+	ImportFormatCSV       = "csv"
+)
+
+// ImportedLink is one link read from another shortener's export. Error is
+// set when the row could not be read, the row is still reported.
+type ImportedLink struct {
+	Row         int
+	OriginalUrl string
+	ShortCode   string
+	Expiration  int64
+	Error       string
+}
+
+// Column names checked in order, after lower-casing the CSV header
+var (
+	bitlyDestinationColumns = []string{"long url", "long_url", "longurl"}
+	bitlyCodeColumns        = []string{"custom bitlink", "bitlink", "link", "id"}
+
+	csvDestinationColumns = []string{"originalurl", "original_url", "original url", "long url", "long_url", "longurl", "destination", "target", "url"}
+	csvCodeColumns        = []string{"alias", "code", "shortcode", "short_code", "short code", "keyword", "slug", "shorturl", "short_url", "short url", "link"}
+)
+
+// ParseImportFile reads an export file in one of the supported formats
+func ParseImportFile(format string, r io.Reader) ([]ImportedLink, error) {
+	switch format {
+	case ImportFormatBitlyCSV:
+		return parseImportCSV(r, bitlyDestinationColumns, bitlyCodeColumns)
+	case ImportFormatBitlyJSON:
+		return parseBitlyJSON(r)
+	case ImportFormatCSV:
+		return parseImportCSV(r, csvDestinationColumns, csvCodeColumns)
+	default:
+		return nil, errors.New("Unsupported import format, use bitly-csv, bitly-json or csv")
+	}
+}
+
+func parseImportCSV(r io.Reader, destinationColumns []string, codeColumns []string) ([]ImportedLink, error) {
+	records, err := ReadCSVRecords(r)
+	if err != nil {
+		return nil, err
+	}
+
+	links := make([]ImportedLink, len(records))
+	for i, record := range records {
+		links[i] = ImportedLink{
+			// Row numbers match the file, counting the header as row 1
+			Row:         i + 2,
+			OriginalUrl: firstColumn(record, destinationColumns),
+			ShortCode:   ShortCodeFromLink(firstColumn(record, codeColumns)),
+		}
+
+		if expiration := record["expiration"]; expiration != "" {
+			seconds, err := strconv.ParseInt(expiration, 10, 64)
+			if err != nil || seconds < 0 {
+				links[i].Error = "Expiration must be a number of seconds"
+			}
+			links[i].Expiration = seconds
+		}
+	}
+
+	return links, nil
+}
+
+type bitlyLink struct {
+	Link           string   `json:"link"`
+	ID             string   `json:"id"`
+	LongUrl        string   `json:"long_url"`
+	CustomBitlinks []string `json:"custom_bitlinks"`
+}
+
+// parseBitlyJSON reads the links API format, either wrapped in a "links"
+// object or as a bare array
+func parseBitlyJSON(r io.Reader) ([]ImportedLink, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrapped struct {
+		Links []bitlyLink `json:"links"`
+	}
+	var bitlyLinks []bitlyLink
+	if err := json.Unmarshal(body, &bitlyLinks); err != nil {
+		if err := json.Unmarshal(body, &wrapped); err != nil {
+			return nil, errors.New("Invalid Bitly JSON export")
+		}
+		bitlyLinks = wrapped.Links
+	}
+
+	links := make([]ImportedLink, len(bitlyLinks))
+	for i, link := range bitlyLinks {
+		// A custom back-half is the code people actually shared
+		code := link.Link
+		if len(link.CustomBitlinks) > 0 {
+			code = link.CustomBitlinks[0]
+		} else if code == "" {
+			code = link.ID
+		}
+
+		links[i] = ImportedLink{
+			Row:         i + 1,
+			OriginalUrl: strings.TrimSpace(link.LongUrl),
+			ShortCode:   ShortCodeFromLink(code),
+		}
+	}
+
+	return links, nil
+}
+
+// ShortCodeFromLink returns the code of a short link such as
+// https://bit.ly/3abcXYZ, or the value itself when it is already a bare code
+func ShortCodeFromLink(link string) string {
+	link = strings.TrimSpace(link)
+	if i := strings.IndexAny(link, "?#"); i >= 0 {
+		link = link[:i]
+	}
+	link = strings.TrimSuffix(link, "/")
+	if i := strings.LastIndex(link, "/"); i >= 0 {
+		link = link[i+1:]
+	}
+	return link
+}
+
+func firstColumn(record map[string]string, columns []string) string {
+	for _, column := range columns {
+		if value := record[column]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
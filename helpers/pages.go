@@ -0,0 +1,146 @@
+package helpers
+
+import (
+	"html/template"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Pages are shown to people following short links, so they are plain HTML
+// rather than the JSON the api returns
+const layoutHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+{{block "head" .}}{{end}}
+<style>
+body { font-family: system-ui, sans-serif; background: #f5f5f5; color: #222; margin: 0; }
+main { max-width: 28rem; margin: 10vh auto; background: #fff; padding: 2rem; border-radius: 8px; box-shadow: 0 1px 4px rgba(0,0,0,.1); }
+h1 { font-size: 1.4rem; margin-top: 0; }
+.error { color: #b00020; }
+.muted { color: #666; font-size: .9rem; }
+.destination { word-break: break-all; }
+input, button, .button { font: inherit; padding: .6rem .8rem; border-radius: 4px; }
+input { width: 100%; box-sizing: border-box; border: 1px solid #ccc; margin-bottom: 1rem; }
+button, .button { background: #222; color: #fff; border: 0; cursor: pointer; text-decoration: none; display: inline-block; }
+</style>
+</head>
+<body>
+<main>
+{{block "content" .}}{{end}}
+</main>
+</body>
+</html>`
+
+var pageContents = map[string]string{
+	"password": `{{define "content"}}
+<h1>This link is password protected</h1>
+<p>Enter the password to continue.</p>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<form method="post" action="{{.Action}}">
+<input type="password" name="password" autocomplete="current-password" autofocus required>
+<button type="submit">Continue</button>
+</form>
+{{end}}`,
+
+	// Tries to open the app and moves on to the fallback when nothing
+	// happens, which is what a browser does when the app isn't installed
+	"deeplink": `{{define "head"}}
+<script>
+window.location.href = {{.AppUrl}};
+setTimeout(function () {
+	if (!document.hidden) { window.location.replace({{.FallbackUrl}}); }
+}, 1500);
+</script>
+{{end}}
+{{define "content"}}
+<h1>Opening the app…</h1>
+<p>If nothing happens, <a href="{{.AppUrl}}">open the app</a> or <a href="{{.FallbackUrl}}">continue in the browser</a>.</p>
+{{end}}`,
+
+	"preview": `{{define "content"}}
+<h1>{{if .LinkTitle}}{{.LinkTitle}}{{else}}Where this link goes{{end}}</h1>
+{{if .Destination}}<p>This link goes to</p>
+<p class="destination"><strong>{{.Destination}}</strong></p>
+{{else}}<p>This link is password protected, the destination is shown after the password is entered.</p>
+{{end}}<p class="muted">Created {{.CreatedAt.Format "2 January 2006"}}</p>
+<a class="button" href="{{.Continue}}" rel="noreferrer">Continue</a>
+{{end}}`,
+
+	// Served to link preview bots in place of a redirect
+	"card": `{{define "head"}}
+<meta property="og:type" content="website">
+<meta property="og:url" content="{{.Url}}">
+<meta property="og:title" content="{{.Title}}">
+<meta name="twitter:title" content="{{.Title}}">
+{{if .Description}}<meta name="description" content="{{.Description}}">
+<meta property="og:description" content="{{.Description}}">
+<meta name="twitter:description" content="{{.Description}}">
+{{end}}{{if .ImageUrl}}<meta property="og:image" content="{{.ImageUrl}}">
+<meta name="twitter:image" content="{{.ImageUrl}}">
+<meta name="twitter:card" content="summary_large_image">
+{{else}}<meta name="twitter:card" content="summary">
+{{end}}{{end}}
+{{define "content"}}
+<h1>{{.Title}}</h1>
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+{{end}}`,
+
+	"message": `{{define "content"}}
+<h1>{{.Title}}</h1>
+<p>{{.Message}}</p>
+{{end}}`,
+}
+
+var pages = map[string]*template.Template{}
+
+func init() {
+	layout := template.Must(template.New("layout").Parse(layoutHTML))
+	for name, content := range pageContents {
+		pages[name] = template.Must(template.Must(layout.Clone()).Parse(content))
+	}
+}
+
+// RenderPage writes one of the HTML pages above
+func RenderPage(c *gin.Context, statusCode int, name string, data gin.H) {
+	page, ok := pages[name]
+	if !ok {
+		log.Println("Unknown page:", name)
+		page = pages["message"]
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Cache-Control", "no-store")
+	c.Status(statusCode)
+	if err := page.Execute(c.Writer, data); err != nil {
+		log.Println("Failed to render page:", err)
+	}
+}
+
+// SendMessagePage shows a short title and message to a visitor
+func SendMessagePage(c *gin.Context, statusCode int, title string, message string) {
+	RenderPage(c, statusCode, "message", gin.H{
+		"Title":   title,
+		"Message": message,
+	})
+}
+
+// WantsJSON reports whether the client asked for JSON rather than a page,
+// browsers always list text/html in their Accept header
+func WantsJSON(c *gin.Context) bool {
+	return c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON
+}
+
+// SendErrorPage shows an error page to browsers and keeps the usual JSON
+// error for api clients
+func SendErrorPage(c *gin.Context, statusCode int, title string, message string) {
+	if WantsJSON(c) {
+		SendError(c, statusCode, message)
+		return
+	}
+	SendMessagePage(c, statusCode, title, message)
+}
@@ -0,0 +1,129 @@
+package helpers
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/manlikehenryy/url-shortener-go/models"
+	"golang.org/x/net/idna"
+)
+
+// PolicyEngine holds the destination deny and allow lists in memory. A
+// destination is blocked when it matches a deny rule and no allow rule, so
+// allow rules act as exceptions to broader deny rules.
+type PolicyEngine struct {
+	mu    sync.RWMutex
+	deny  []compiledPolicyRule
+	allow []compiledPolicyRule
+}
+
+type compiledPolicyRule struct {
+	rule  models.PolicyRule
+	regex *regexp.Regexp
+}
+
+// NormalizePolicyRule validates a rule and puts its pattern in the form it
+// is matched in
+func NormalizePolicyRule(rule *models.PolicyRule) error {
+	rule.Pattern = strings.TrimSpace(rule.Pattern)
+
+	switch rule.Match {
+	case models.PolicyMatchExact, models.PolicyMatchWildcard:
+		domain := rule.Pattern
+		if rule.Match == models.PolicyMatchWildcard {
+			if !strings.HasPrefix(domain, "*.") {
+				return errors.New("wildcard patterns must start with *.")
+			}
+			domain = domain[2:]
+		}
+
+		domain, err := idna.Lookup.ToASCII(strings.TrimSuffix(domain, "."))
+		if err != nil || domain == "" || strings.ContainsAny(domain, "*/:") {
+			return errors.New("pattern must be a domain name")
+		}
+		domain = strings.ToLower(domain)
+
+		if rule.Match == models.PolicyMatchWildcard {
+			rule.Pattern = "*." + domain
+		} else {
+			rule.Pattern = domain
+		}
+	case models.PolicyMatchRegex:
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return errors.New("pattern is not a valid regular expression")
+		}
+	default:
+		return errors.New("match must be exact, wildcard or regex")
+	}
+
+	return nil
+}
+
+// Load replaces the rules in the engine, rules that fail to compile are skipped
+func (e *PolicyEngine) Load(rules []models.PolicyRule) {
+	var deny, allow []compiledPolicyRule
+	for _, rule := range rules {
+		compiled := compiledPolicyRule{rule: rule}
+		if rule.Match == models.PolicyMatchRegex {
+			regex, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				continue
+			}
+			compiled.regex = regex
+		}
+
+		if rule.Action == models.PolicyActionAllow {
+			allow = append(allow, compiled)
+		} else {
+			deny = append(deny, compiled)
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.deny = deny
+	e.allow = allow
+}
+
+// Check returns the deny rule that blocks a destination, or nil when the
+// destination is allowed
+func (e *PolicyEngine) Check(destination string) *models.PolicyRule {
+	parsed, err := url.Parse(destination)
+	if err != nil {
+		return nil
+	}
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, rule := range e.allow {
+		if rule.matches(host, destination) {
+			return nil
+		}
+	}
+
+	for _, rule := range e.deny {
+		if rule.matches(host, destination) {
+			blocking := rule.rule
+			return &blocking
+		}
+	}
+
+	return nil
+}
+
+func (r compiledPolicyRule) matches(host string, destination string) bool {
+	switch r.rule.Match {
+	case models.PolicyMatchExact:
+		return host == r.rule.Pattern
+	case models.PolicyMatchWildcard:
+		return strings.HasSuffix(host, r.rule.Pattern[1:])
+	case models.PolicyMatchRegex:
+		return r.regex.MatchString(destination)
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/manlikehenryy/url-shortener-go/models"
+)
+
+func TestPolicyEngineCheck(t *testing.T) {
+	engine := &PolicyEngine{}
+	engine.Load([]models.PolicyRule{
+		{Action: models.PolicyActionDeny, Match: models.PolicyMatchExact, Pattern: "evil.com"},
+		{Action: models.PolicyActionDeny, Match: models.PolicyMatchWildcard, Pattern: "*.bad.org"},
+		{Action: models.PolicyActionAllow, Match: models.PolicyMatchExact, Pattern: "good.bad.org"},
+		{Action: models.PolicyActionDeny, Match: models.PolicyMatchRegex, Pattern: `^https?://[^/]+/phish`},
+		{Action: models.PolicyActionDeny, Match: models.PolicyMatchRegex, Pattern: `(`}, // doesn't compile, skipped
+	})
+
+	tests := []struct {
+		destination string
+		blockedBy   string // pattern of the blocking rule, "" when allowed
+	}{
+		{destination: "https://evil.com/page", blockedBy: "evil.com"},
+		{destination: "https://EVIL.com./page", blockedBy: "evil.com"},
+		{destination: "https://sub.evil.com/page"},
+		{destination: "https://a.bad.org/", blockedBy: "*.bad.org"},
+		{destination: "https://deep.a.bad.org/", blockedBy: "*.bad.org"},
+		{destination: "https://bad.org/"},
+		{destination: "https://good.bad.org/"},
+		{destination: "https://example.com/phishing", blockedBy: `^https?://[^/]+/phish`},
+		{destination: "https://example.com/ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.destination, func(t *testing.T) {
+			rule := engine.Check(tt.destination)
+			switch {
+			case tt.blockedBy == "" && rule != nil:
+				t.Errorf("Check() blocked by %q, want allowed", rule.Pattern)
+			case tt.blockedBy != "" && rule == nil:
+				t.Errorf("Check() allowed, want blocked by %q", tt.blockedBy)
+			case rule != nil && rule.Pattern != tt.blockedBy:
+				t.Errorf("Check() blocked by %q, want %q", rule.Pattern, tt.blockedBy)
+			}
+		})
+	}
+}
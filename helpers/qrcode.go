@@ -0,0 +1,220 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	"image/png"
+	"net/http"
+	"os"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Part of the code's width a logo may cover, level H recovers up to 30% of
+// the modules
+const qrLogoRatio = 0.22
+
+// QRLogo is an image placed in the middle of a QR code
+type QRLogo struct {
+	Image    image.Image
+	Data     []byte // the original file, embedded as is in SVG codes
+	MimeType string
+}
+
+// QROptions control how a QR code is drawn. Size is in pixels and Margin
+// is the quiet zone around the code in modules.
+type QROptions struct {
+	Size       int
+	Margin     int
+	Level      qrcode.RecoveryLevel
+	Foreground color.RGBA
+	Background color.RGBA
+	Logo       *QRLogo
+}
+
+var qrLevels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// ParseQRLevel reads an error-correction level, one of L, M, Q or H
+func ParseQRLevel(level string) (qrcode.RecoveryLevel, error) {
+	recoveryLevel, ok := qrLevels[strings.ToUpper(level)]
+	if !ok {
+		return 0, errors.New("must be one of L, M, Q or H")
+	}
+	return recoveryLevel, nil
+}
+
+// ParseHexColor reads a color written as RRGGBB or #RRGGBB
+func ParseHexColor(hex string) (color.RGBA, error) {
+	hex = strings.TrimPrefix(hex, "#")
+	var r, g, b uint8
+	if len(hex) != 6 {
+		return color.RGBA{}, errors.New("must be a hex color such as 1a2b3c")
+	}
+	if _, err := fmt.Sscanf(hex, "%02x%02x%02x", &r, &g, &b); err != nil {
+		return color.RGBA{}, errors.New("must be a hex color such as 1a2b3c")
+	}
+	return color.RGBA{R: r, G: g, B: b, A: 0xff}, nil
+}
+
+// LoadQRLogo reads a PNG or JPEG logo
+func LoadQRLogo(path string) (*QRLogo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding logo: %w", err)
+	}
+
+	return &QRLogo{Image: img, Data: data, MimeType: http.DetectContentType(data)}, nil
+}
+
+// QRTooSmallError is returned when Size leaves less than a pixel for each
+// module, MinSize is the smallest size the code can be drawn at
+type QRTooSmallError struct {
+	MinSize int
+}
+
+func (e *QRTooSmallError) Error() string {
+	return fmt.Sprintf("must be at least %d for this link", e.MinSize)
+}
+
+// qrModules is the code's modules, true for dark, with the margin added.
+// Long links need more modules, so Size is only checked once they are known.
+func qrModules(content string, options QROptions) ([][]bool, error) {
+	code, err := qrcode.New(content, options.Level)
+	if err != nil {
+		return nil, err
+	}
+	code.DisableBorder = true
+	bitmap := code.Bitmap()
+
+	size := len(bitmap) + 2*options.Margin
+	if options.Size < size {
+		return nil, &QRTooSmallError{MinSize: size}
+	}
+
+	modules := make([][]bool, size)
+	for y := range modules {
+		modules[y] = make([]bool, size)
+	}
+	for y, row := range bitmap {
+		copy(modules[y+options.Margin][options.Margin:], row)
+	}
+	return modules, nil
+}
+
+// RenderQRPNG draws a QR code as a PNG of exactly Size by Size pixels
+func RenderQRPNG(content string, options QROptions) ([]byte, error) {
+	modules, err := qrModules(content, options)
+	if err != nil {
+		return nil, err
+	}
+
+	count := len(modules)
+	img := image.NewRGBA(image.Rect(0, 0, options.Size, options.Size))
+	for y := 0; y < options.Size; y++ {
+		for x := 0; x < options.Size; x++ {
+			if modules[y*count/options.Size][x*count/options.Size] {
+				img.SetRGBA(x, y, options.Foreground)
+			} else {
+				img.SetRGBA(x, y, options.Background)
+			}
+		}
+	}
+
+	if options.Logo != nil {
+		drawQRLogo(img, options)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawQRLogo scales the logo into the middle of the code on a patch of
+// background color
+func drawQRLogo(img *image.RGBA, options QROptions) {
+	logo := options.Logo.Image
+	bounds := logo.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return
+	}
+
+	box := int(float64(options.Size) * qrLogoRatio)
+	width, height := box, box*bounds.Dy()/bounds.Dx()
+	if height > box {
+		width, height = box*bounds.Dx()/bounds.Dy(), box
+	}
+
+	center := options.Size / 2
+	padding := options.Size / 100
+	patch := image.Rect(center-width/2-padding, center-height/2-padding, center+width/2+padding, center+height/2+padding)
+	draw.Draw(img, patch, &image.Uniform{C: options.Background}, image.Point{}, draw.Src)
+
+	// Nearest neighbour scaling, blended over the background for transparent logos
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			scaled.Set(x, y, logo.At(bounds.Min.X+x*bounds.Dx()/width, bounds.Min.Y+y*bounds.Dy()/height))
+		}
+	}
+	target := image.Rect(center-width/2, center-height/2, center-width/2+width, center-height/2+height)
+	draw.Draw(img, target, scaled, image.Point{}, draw.Over)
+}
+
+// RenderQRSVG draws a QR code as an SVG Size pixels wide
+func RenderQRSVG(content string, options QROptions) ([]byte, error) {
+	modules, err := qrModules(content, options)
+	if err != nil {
+		return nil, err
+	}
+
+	count := len(modules)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		options.Size, options.Size, count, count)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="%s"/>`, count, count, hexColor(options.Background))
+
+	buf.WriteString(`<path fill="` + hexColor(options.Foreground) + `" d="`)
+	for y, row := range modules {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	buf.WriteString(`"/>`)
+
+	if options.Logo != nil {
+		box := float64(count) * qrLogoRatio
+		offset := (float64(count) - box) / 2
+		fmt.Fprintf(&buf, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s"/>`,
+			offset-0.5, offset-0.5, box+1, box+1, hexColor(options.Background))
+		fmt.Fprintf(&buf, `<image x="%.2f" y="%.2f" width="%.2f" height="%.2f" href="data:%s;base64,%s"/>`,
+			offset, offset, box, box, options.Logo.MimeType, base64.StdEncoding.EncodeToString(options.Logo.Data))
+	}
+
+	buf.WriteString("</svg>")
+	return buf.Bytes(), nil
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,47 @@
+package helpers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const unlockAudience = "unlock"
+
+// GenerateUnlockToken signs a token proving the visitor entered the
+// password of a protected url. It is tied to the current password hash so
+// changing the password locks out earlier visitors.
+func GenerateUnlockToken(shortURL string, passwordHash []byte, ttl time.Duration) (string, error) {
+	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{
+		Subject:   shortURL,
+		Audience:  unlockAudience,
+		Id:        passwordFingerprint(passwordHash),
+		ExpiresAt: time.Now().Add(ttl).Unix(),
+	})
+
+	return claims.SignedString([]byte(secretKey))
+}
+
+func ValidUnlockToken(token string, shortURL string, passwordHash []byte) bool {
+	claims := &jwt.StandardClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(secretKey), nil
+	})
+	if err != nil || !parsed.Valid {
+		return false
+	}
+
+	return claims.Subject == shortURL &&
+		claims.Audience == unlockAudience &&
+		claims.Id == passwordFingerprint(passwordHash)
+}
+
+func passwordFingerprint(passwordHash []byte) string {
+	sum := sha256.Sum256(passwordHash)
+	return hex.EncodeToString(sum[:8])
+}
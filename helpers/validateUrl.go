@@ -0,0 +1,191 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/idna"
+)
+
+var AllowedSchemes = []string{"http", "https"}
+
+const maxRedirectHops = 5
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// NormalizeDestination validates a destination url and returns it in a
+// canonical form: lower-case scheme and host, punycode for international
+// domain names and no default port. isSelfHost reports whether a host
+// belongs to the shortener, links that point back at it are rejected since
+// they would loop.
+func NormalizeDestination(raw string, isSelfHost func(host string) bool) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", errors.New("is required")
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", errors.New("must be a valid URL")
+	}
+
+	if parsed.Scheme == "" {
+		return "", errors.New("must be an absolute URL starting with http:// or https://")
+	}
+
+	if !isAllowedScheme(parsed.Scheme) {
+		return "", fmt.Errorf("scheme %q is not allowed, use %s", parsed.Scheme, strings.Join(AllowedSchemes, " or "))
+	}
+
+	if parsed.User != nil {
+		return "", errors.New("must not contain a username or password")
+	}
+
+	hostname := strings.TrimSuffix(parsed.Hostname(), ".")
+	if hostname == "" {
+		return "", errors.New("must include a host")
+	}
+
+	if net.ParseIP(hostname) == nil {
+		hostname, err = idna.Lookup.ToASCII(hostname)
+		if err != nil {
+			return "", errors.New("host is not a valid domain name")
+		}
+	}
+	hostname = strings.ToLower(hostname)
+
+	port := parsed.Port()
+	if port == defaultPorts[parsed.Scheme] {
+		port = ""
+	}
+
+	if port != "" {
+		parsed.Host = net.JoinHostPort(hostname, port)
+	} else if strings.Contains(hostname, ":") {
+		parsed.Host = "[" + hostname + "]"
+	} else {
+		parsed.Host = hostname
+	}
+
+	if isSelfHost(parsed.Host) {
+		return "", errors.New("must not point back to this url shortener")
+	}
+
+	return parsed.String(), nil
+}
+
+// HostFromAppURL returns the host of APP_URL, which may be set with or
+// without a scheme
+func HostFromAppURL(appURL string) string {
+	host := appURL
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+	return strings.ToLower(host)
+}
+
+// IsSelfHost reports whether host (with an optional port) is the
+// shortener's own host. A selfHost without a port matches any port.
+func IsSelfHost(host string, selfHost string) bool {
+	if selfHost == "" {
+		return false
+	}
+
+	host = strings.ToLower(host)
+	if host == selfHost {
+		return true
+	}
+
+	if _, _, err := net.SplitHostPort(selfHost); err != nil {
+		hostname := host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			hostname = h
+		}
+		return strings.Trim(hostname, "[]") == strings.Trim(selfHost, "[]")
+	}
+
+	return false
+}
+
+// DetectRedirectLoop follows the destination's redirect chain for a few hops
+// and reports an error if it leads back to the shortener or revisits a url.
+// Hops that can't be fetched end the check without an error.
+func DetectRedirectLoop(destination string, isSelfHost func(host string) bool) error {
+	// Refuse to connect to internal addresses so the check can't be used to
+	// probe the server's own network
+	dialer := &net.Dialer{
+		Timeout: 3 * time.Second,
+		Control: func(network, address string, conn syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if isInternalIP(net.ParseIP(host)) {
+				return errors.New("refusing to connect to an internal address")
+			}
+			return nil
+		},
+	}
+
+	client := &http.Client{
+		Timeout:   3 * time.Second,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	visited := map[string]bool{destination: true}
+	current := destination
+	for hop := 0; hop < maxRedirectHops; hop++ {
+		resp, err := client.Head(current)
+		if err != nil {
+			return nil
+		}
+		resp.Body.Close()
+
+		location, err := resp.Location()
+		if err != nil {
+			// Not a redirect, the chain ends here
+			return nil
+		}
+
+		if isSelfHost(location.Host) {
+			return errors.New("redirects back to this url shortener")
+		}
+
+		next := location.String()
+		if visited[next] {
+			return errors.New("redirects in a loop")
+		}
+		visited[next] = true
+		current = next
+	}
+
+	return nil
+}
+
+func isAllowedScheme(scheme string) bool {
+	for _, allowed := range AllowedSchemes {
+		if scheme == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func isInternalIP(ip net.IP) bool {
+	return ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()
+}
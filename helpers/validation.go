@@ -0,0 +1,66 @@
+package helpers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldErrors maps a JSON field name to what is wrong with it
+type FieldErrors map[string]string
+
+func (e FieldErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field + ": " + e[field]
+	}
+	return strings.Join(messages, "; ")
+}
+
+// BindingFieldErrors converts the errors from gin's binding tags into
+// FieldErrors, ok is false for any other kind of error
+func BindingFieldErrors(err error) (FieldErrors, bool) {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil, false
+	}
+
+	fieldErrors := FieldErrors{}
+	for _, fieldError := range validationErrors {
+		// Struct fields are the json names with an upper case first letter
+		name := fieldError.Field()
+		name = strings.ToLower(name[:1]) + name[1:]
+
+		if fieldError.Tag() == "required" {
+			fieldErrors[name] = "is required"
+		} else {
+			fieldErrors[name] = "is invalid"
+		}
+	}
+
+	return fieldErrors, true
+}
+
+// SendValidationError responds with the field errors when err carries them
+func SendValidationError(c *gin.Context, err error) {
+	var fieldErrors FieldErrors
+	if errors.As(err, &fieldErrors) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": fieldErrors,
+		})
+		return
+	}
+
+	SendError(c, http.StatusBadRequest, err.Error())
+}
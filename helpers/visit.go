@@ -0,0 +1,17 @@
+package helpers
+
+import "github.com/gin-gonic/gin"
+
+const notAVisitKey = "notAVisit"
+
+// NotAVisit marks a request to a short link that didn't send the visitor on,
+// such as one that showed the password or preview page. It isn't counted
+// against the visit rate limit.
+func NotAVisit(c *gin.Context) {
+	c.Set(notAVisitKey, true)
+}
+
+// IsVisit reports whether a request to a short link was a visit
+func IsVisit(c *gin.Context) bool {
+	return !c.GetBool(notAVisitKey)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
 	"github.com/manlikehenryy/url-shortener-go/configs"
+	"github.com/manlikehenryy/url-shortener-go/controllers"
 	"github.com/manlikehenryy/url-shortener-go/database"
 	"github.com/manlikehenryy/url-shortener-go/routes"
 
@@ -19,6 +20,7 @@ func main() {
 
 
 	helpers.Initialize()
+	helpers.OpenGeoIP(configs.Env.GEOIP_DB_PATH)
 
 	// Connect to the database
 	database.Connect()
@@ -39,6 +41,9 @@ func main() {
 	// Set up routes
 	routes.Setup(app)
 
+	// Start background jobs
+	controllers.StartBackgroundJobs()
+
 	// Start the server
 	err := app.Run(":" + port)
 	if err != nil {
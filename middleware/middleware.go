@@ -9,7 +9,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/manlikehenryy/url-shortener-go/database"
 	"github.com/manlikehenryy/url-shortener-go/helpers"
+	"github.com/manlikehenryy/url-shortener-go/models"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 var ctx = context.Background()
@@ -25,14 +28,12 @@ func RateLimit(c *gin.Context) {
 	count, err := database.RDB.Incr(ctx, key).Result()
 	if err != nil {
 		c.Next()
+		return
 	}
 
 	// Set expiration for the key if it's new
 	if count == 1 {
-		err := database.RDB.Expire(ctx, key, time.Minute).Err()
-		if err != nil {
-			c.Next()
-		}
+		database.RDB.Expire(ctx, key, time.Minute)
 	}
 
 	// If the count exceeds the limit, reject the request
@@ -43,6 +44,12 @@ func RateLimit(c *gin.Context) {
 	}
 
 	c.Next()
+
+	// Pages shown on the way to the link, such as the password and preview
+	// pages, give the request back
+	if !helpers.IsVisit(c) {
+		database.RDB.Decr(ctx, key)
+	}
 }
 
 func IsAuthenticated(c *gin.Context) {
@@ -76,3 +83,34 @@ func IsAuthenticated(c *gin.Context) {
 	// Continue to the next middleware or handler
 	c.Next()
 }
+
+// IsAdmin must run after IsAuthenticated, it looks the user up so that
+// revoking admin access takes effect straight away
+func IsAdmin(c *gin.Context) {
+	userId, ok := c.MustGet("userId").(primitive.ObjectID)
+	if !ok {
+		helpers.SendError(c, http.StatusUnauthorized, "User ID not found in context")
+		c.Abort()
+		return
+	}
+
+	var user models.User
+	err := database.DB.Collection("users").FindOne(ctx, bson.M{"_id": userId}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			helpers.SendError(c, http.StatusUnauthorized, "Unauthorized: User not found")
+		} else {
+			helpers.SendError(c, http.StatusInternalServerError, "Failed to retrieve user")
+		}
+		c.Abort()
+		return
+	}
+
+	if !user.IsAdmin {
+		helpers.SendError(c, http.StatusForbidden, "Forbidden: Admin access required")
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
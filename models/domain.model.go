@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Domain is a branded domain a user serves their links from. Links can
+// only be created on it once the DNS TXT record proves ownership.
+type Domain struct {
+	ID                primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Host              string             `json:"host" bson:"host"` // lower-case, punycode, no port
+	UserId            primitive.ObjectID `json:"userId" bson:"userId"`
+	VerificationToken string             `json:"verificationToken" bson:"verificationToken"`
+	Verified          bool               `json:"verified" bson:"verified"`
+	VerifiedAt        *time.Time         `json:"verifiedAt,omitempty" bson:"verifiedAt,omitempty"`
+	CreatedAt         time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt         time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ImportStatusPending   = "pending" // dry run report ready, nothing created yet
+	ImportStatusRunning   = "running" // links are being created
+	ImportStatusCompleted = "completed"
+
+	ImportActionKeepCode = "keep_code"
+	ImportActionNewCode  = "new_code"
+	ImportActionSkip     = "skip"
+)
+
+type ImportJob struct {
+	ID          primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	UserId      primitive.ObjectID `json:"userId" bson:"userId"`
+	Domain      string             `json:"domain,omitempty" bson:"domain,omitempty"` // branded domain the links are created on
+	Format      string             `json:"format" bson:"format"`
+	FileName    string             `json:"fileName" bson:"fileName"`
+	Status      string             `json:"status" bson:"status"`
+	Summary     ImportSummary      `json:"summary" bson:"summary"`
+	Items       []ImportItem       `json:"items" bson:"items"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt" bson:"updatedAt"`
+	CompletedAt *time.Time         `json:"completedAt,omitempty" bson:"completedAt,omitempty"`
+}
+
+type ImportItem struct {
+	Row         int                 `json:"row" bson:"row"`
+	OriginalUrl string              `json:"originalUrl" bson:"originalUrl"`
+	SourceCode  string              `json:"sourceCode" bson:"sourceCode"` // code used by the previous service
+	Expiration  int64               `json:"expiration" bson:"expiration"`
+	Action      string              `json:"action" bson:"action"`
+	ShortUrl    string              `json:"shortUrl,omitempty" bson:"shortUrl,omitempty"`
+	UrlId       *primitive.ObjectID `json:"urlId,omitempty" bson:"urlId,omitempty"`
+	Created     bool                `json:"created" bson:"created"`
+	Message     string              `json:"message,omitempty" bson:"message,omitempty"`
+}
+
+type ImportSummary struct {
+	Total    int `json:"total" bson:"total"`
+	KeepCode int `json:"keepCode" bson:"keepCode"`
+	NewCode  int `json:"newCode" bson:"newCode"`
+	Skipped  int `json:"skipped" bson:"skipped"`
+	Created  int `json:"created" bson:"created"`
+	Failed   int `json:"failed" bson:"failed"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	PolicyActionDeny  = "deny"
+	PolicyActionAllow = "allow"
+
+	PolicyMatchExact    = "exact"    // the host itself, e.g. example.com
+	PolicyMatchWildcard = "wildcard" // any subdomain, e.g. *.example.com
+	PolicyMatchRegex    = "regex"    // matched against the full destination url
+)
+
+type PolicyRule struct {
+	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Action    string             `json:"action" bson:"action" binding:"required,oneof=deny allow"`
+	Match     string             `json:"match" bson:"match" binding:"required,oneof=exact wildcard regex"`
+	Pattern   string             `json:"pattern" bson:"pattern" binding:"required,max=500"`
+	Note      string             `json:"note" bson:"note"`
+	CreatedBy primitive.ObjectID `json:"createdBy" bson:"createdBy"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
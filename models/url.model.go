@@ -1,24 +1,258 @@
 package models
 
 import (
+	"net/http"
+	"slices"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	ExpirationDuration = "duration" // expires a fixed number of seconds after it is saved
+	ExpirationAbsolute = "absolute" // expires at expiresAt
+	ExpirationNever    = "never"
+	ExpirationSliding  = "sliding" // every click pushes expiry back by expiration seconds
+)
+
+const (
+	UrlStatusActive    = "active"
+	UrlStatusDisabled  = "disabled" // paused by its owner
+	UrlStatusDeleted   = "deleted"  // in the trash until it is restored or purged
+	UrlStatusExpired   = "expired"
+	UrlStatusExhausted = "exhausted" // reached maxClicks
+	UrlStatusScheduled = "scheduled" // activeFrom is still in the future
+)
+
+// What happens to the query string a short link is visited with
+const (
+	QueryPassthroughOff      = "off"      // dropped
+	QueryPassthroughKeep     = "keep"     // merged, the destination's value wins a conflict
+	QueryPassthroughOverride = "override" // merged, the incoming value wins a conflict
+	QueryPassthroughAppend   = "append"   // merged, both values are kept
+)
+
+// RedirectTypes are the status codes a url can redirect with, 307 and 308
+// keep the request method
+var RedirectTypes = []int{
+	http.StatusMovedPermanently,
+	http.StatusFound,
+	http.StatusTemporaryRedirect,
+	http.StatusPermanentRedirect,
+}
+
 type Url struct {
-	ID           primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
-	ShortUrl     string             `json:"shortUrl" bson:"shortUrl"`
-	OriginalUrl  string             `json:"originalUrl" bson:"originalUrl" binding:"required"`
-	Expiration   int64              `json:"expiration" bson:"expiration" binding:"required"` //in seconds
-	ClickCount   int                `bson:"clickCount"`
-	ClickDetails []Click            `bson:"clickDetails"`
-	UserId       primitive.ObjectID `json:"userId" bson:"userId"`
-	CreatedAt    time.Time          `json:"createdAt" bson:"createdAt"`
-	UpdatedAt    time.Time          `json:"updatedAt" bson:"updatedAt"`
+	ID               primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	ShortUrl         string             `json:"shortUrl" bson:"shortUrl"`
+	Domain           string             `json:"domain,omitempty" bson:"domain,omitempty"` // branded domain, the shortener's own when empty
+	Alias            string             `json:"alias,omitempty" bson:"-"`                 // optional custom code, only read on create
+	Dedupe           *bool              `json:"dedupe,omitempty" bson:"-"`                // overrides the account setting, only read on create
+	OriginalUrl      string             `json:"originalUrl" bson:"originalUrl" binding:"required"`
+	Title            string             `json:"title,omitempty" bson:"title,omitempty"`             // shown on the preview page
+	ForcePreview     bool               `json:"forcePreview" bson:"forcePreview"`                   // show the preview page before redirecting
+	FallbackUrl      string             `json:"fallbackUrl,omitempty" bson:"fallbackUrl,omitempty"` // visitors go here once the link has expired or run out of clicks
+	SocialCard       *SocialCard        `json:"socialCard,omitempty" bson:"socialCard,omitempty"`   // shown to link preview bots instead of the destination's
+	Expiration       int64              `json:"expiration" bson:"expiration"`                       //in seconds
+	ExpirationType   string             `json:"expirationType" bson:"expirationType"`
+	ExpiresAt        *time.Time         `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+	ActiveFrom       *time.Time         `json:"activeFrom,omitempty" bson:"activeFrom,omitempty"`
+	MaxClicks        int64              `json:"maxClicks" bson:"maxClicks"` // 0 for no limit
+	RedirectType     int                `json:"redirectType" bson:"redirectType,omitempty"`
+	QueryPassthrough string             `json:"queryPassthrough" bson:"queryPassthrough,omitempty"`
+	Utm              *UtmParams         `json:"utm,omitempty" bson:"utm,omitempty"`
+	DeepLinks        *DeepLinks         `json:"deepLinks,omitempty" bson:"deepLinks,omitempty"`
+	GeoTargets       map[string]string  `json:"geoTargets,omitempty" bson:"geoTargets,omitempty"` // country code to destination, others go to originalUrl
+	Variants         []Variant          `json:"variants,omitempty" bson:"variants,omitempty"`
+	StickyVariants   bool               `json:"stickyVariants" bson:"stickyVariants"`                   // remember each visitor's variant with a cookie
+	ScheduleRules    []ScheduleRule     `json:"scheduleRules,omitempty" bson:"scheduleRules,omitempty"` // the first matching rule picks the destination
+	Password         *string            `json:"password,omitempty" bson:"-"`                            // only read on create and update, "" removes it
+	PasswordHash     []byte             `json:"-" bson:"passwordHash,omitempty"`
+	Protected        bool               `json:"protected" bson:"protected"`
+	Disabled         bool               `json:"disabled" bson:"disabled"`
+	DeletedAt        *time.Time         `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"` // in the trash since
+	ExhaustedAt      *time.Time         `json:"exhaustedAt,omitempty" bson:"exhaustedAt,omitempty"`
+	Status           string             `json:"status" bson:"-"`
+	ClickCount       int                `bson:"clickCount"`
+	ClickDetails     []Click            `bson:"clickDetails"`
+	UserId           primitive.ObjectID `json:"userId" bson:"userId"`
+	Version          int                `json:"version" bson:"version"` // latest UrlRevision
+	CreatedAt        time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt        time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// ComputeStatus works out the status reported to the link's owner
+func (url *Url) ComputeStatus(now time.Time) string {
+	switch {
+	case url.DeletedAt != nil:
+		return UrlStatusDeleted
+	case url.Disabled:
+		return UrlStatusDisabled
+	case url.ExhaustedAt != nil:
+		return UrlStatusExhausted
+	case url.ExpiresAt != nil && !url.ExpiresAt.After(now):
+		return UrlStatusExpired
+	case url.IsScheduled(now):
+		return UrlStatusScheduled
+	default:
+		return UrlStatusActive
+	}
+}
+
+// SetPassword protects the url with a passcode. It uses bcrypt's default
+// cost rather than the one for account passwords, links are created in bulk
+// and unlocked by anyone, and attempts are already rate limited.
+func (url *Url) SetPassword(password string) {
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	url.PasswordHash = hashedPassword
+	url.Protected = true
+}
+
+func (url *Url) RemovePassword() {
+	url.PasswordHash = nil
+	url.Protected = false
+}
+
+func (url *Url) ComparePassword(password string) error {
+	return bcrypt.CompareHashAndPassword(url.PasswordHash, []byte(password))
+}
+
+// RedirectStatus is the status code visitors are redirected with, urls
+// saved before redirectType existed use 302
+func (url *Url) RedirectStatus() int {
+	if url.RedirectType == 0 {
+		return http.StatusFound
+	}
+	return url.RedirectType
+}
+
+// IsScheduled reports whether the url has yet to go live
+func (url *Url) IsScheduled(now time.Time) bool {
+	return url.ActiveFrom != nil && now.Before(*url.ActiveFrom)
+}
+
+// SocialCard is the Open Graph and Twitter card content for a short link
+type SocialCard struct {
+	Title       string `json:"title,omitempty" bson:"title,omitempty"`
+	Description string `json:"description,omitempty" bson:"description,omitempty"`
+	ImageUrl    string `json:"imageUrl,omitempty" bson:"imageUrl,omitempty"`
+}
+
+// UtmParams are added to the destination as utm_* query parameters when
+// a url is visited, replacing any the destination already has
+type UtmParams struct {
+	Source   string `json:"source,omitempty" bson:"source,omitempty"`
+	Medium   string `json:"medium,omitempty" bson:"medium,omitempty"`
+	Campaign string `json:"campaign,omitempty" bson:"campaign,omitempty"`
+	Term     string `json:"term,omitempty" bson:"term,omitempty"`
+	Content  string `json:"content,omitempty" bson:"content,omitempty"`
+}
+
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformDesktop = "desktop" // anything that isn't iOS or Android
+)
+
+// DeepLinks are per-platform destinations, visitors on a platform without
+// one go to originalUrl
+type DeepLinks struct {
+	IOS     *AppLink `json:"ios,omitempty" bson:"ios,omitempty"`
+	Android *AppLink `json:"android,omitempty" bson:"android,omitempty"`
+	Desktop string   `json:"desktop,omitempty" bson:"desktop,omitempty"` // replaces originalUrl on desktop
+}
+
+// AppLink opens a mobile app. The app is tried first when AppUrl is set and
+// the store or web page is the fallback when it isn't installed.
+type AppLink struct {
+	AppUrl   string `json:"appUrl,omitempty" bson:"appUrl,omitempty"`     // custom scheme, universal link or Android intent:// url
+	StoreUrl string `json:"storeUrl,omitempty" bson:"storeUrl,omitempty"` // App Store or Play listing
+}
+
+// ForPlatform is the app link for a platform, nil when there isn't one
+func (links *DeepLinks) ForPlatform(platform string) *AppLink {
+	if links == nil {
+		return nil
+	}
+	switch platform {
+	case PlatformIOS:
+		return links.IOS
+	case PlatformAndroid:
+		return links.Android
+	}
+	return nil
+}
+
+// Variant is one destination of an A/B split, visitors are sent to it in
+// proportion to its weight
+type Variant struct {
+	Name   string `json:"name" bson:"name"`
+	Url    string `json:"url" bson:"url"`
+	Weight int    `json:"weight" bson:"weight"`
+}
+
+// Weekdays are the day names schedule rules use, in time.Weekday order
+var Weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// ScheduleRule sends visitors to Destination on the given days, within the
+// time window and date range, in the rule's time zone. Left out fields
+// don't restrict it.
+type ScheduleRule struct {
+	Days        []string `json:"days,omitempty" bson:"days,omitempty"`           // mon to sun, for a window past midnight the day it starts
+	StartTime   string   `json:"startTime,omitempty" bson:"startTime,omitempty"` // HH:MM, inclusive
+	EndTime     string   `json:"endTime,omitempty" bson:"endTime,omitempty"`     // HH:MM, exclusive, before startTime for a window past midnight
+	StartDate   string   `json:"startDate,omitempty" bson:"startDate,omitempty"` // YYYY-MM-DD, inclusive
+	EndDate     string   `json:"endDate,omitempty" bson:"endDate,omitempty"`     // YYYY-MM-DD, inclusive
+	TimeZone    string   `json:"timeZone,omitempty" bson:"timeZone,omitempty"`   // IANA name, UTC when empty
+	Destination string   `json:"destination" bson:"destination"`
+}
+
+// Matches reports whether the rule applies at now. Rules are validated when
+// they are saved, a rule that can't be read never matches.
+func (rule *ScheduleRule) Matches(now time.Time) bool {
+	location, err := time.LoadLocation(rule.TimeZone)
+	if err != nil {
+		return false
+	}
+	local := now.In(location)
+
+	date := local.Format("2006-01-02")
+	if (rule.StartDate != "" && date < rule.StartDate) || (rule.EndDate != "" && date > rule.EndDate) {
+		return false
+	}
+
+	day := local.Weekday()
+	if rule.StartTime != "" {
+		start, errStart := time.Parse("15:04", rule.StartTime)
+		end, errEnd := time.Parse("15:04", rule.EndTime)
+		if errStart != nil || errEnd != nil {
+			return false
+		}
+
+		minute := local.Hour()*60 + local.Minute()
+		startMinute := start.Hour()*60 + start.Minute()
+		endMinute := end.Hour()*60 + end.Minute()
+
+		switch {
+		case startMinute < endMinute:
+			if minute < startMinute || minute >= endMinute {
+				return false
+			}
+		case minute >= startMinute:
+			// Late part of a window past midnight
+		case minute < endMinute:
+			// Early part of a window past midnight, which started yesterday
+			day = (day + 6) % 7
+		default:
+			return false
+		}
+	}
+
+	return len(rule.Days) == 0 || slices.Contains(rule.Days, Weekdays[day])
 }
 
 type Click struct {
 	IPAddress string    `bson:"ipAddress"`
 	Timestamp time.Time `bson:"timestamp"`
+	Variant   string    `bson:"variant,omitempty"` // the A/B variant served
 }
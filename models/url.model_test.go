@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleRuleMatches(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	// 2024-10-18 is a Friday
+	fridayNight := ScheduleRule{Days: []string{"fri"}, StartTime: "22:00", EndTime: "02:00"}
+	workingHours := ScheduleRule{Days: []string{"mon"}, StartTime: "09:00", EndTime: "17:00", TimeZone: "America/New_York"}
+	weekend := ScheduleRule{StartDate: "2024-10-19", EndDate: "2024-10-20"}
+
+	tests := []struct {
+		name string
+		rule ScheduleRule
+		now  string
+		want bool
+	}{
+		{name: "window past midnight at its start", rule: fridayNight, now: "2024-10-18 22:00", want: true},
+		{name: "window past midnight after midnight", rule: fridayNight, now: "2024-10-19 01:30", want: true},
+		{name: "window past midnight at its end", rule: fridayNight, now: "2024-10-19 02:00"},
+		{name: "early hours of the day it starts", rule: fridayNight, now: "2024-10-18 01:30"},
+		{name: "window past midnight on another day", rule: fridayNight, now: "2024-10-19 22:30"},
+		{name: "window in the rule's time zone", rule: workingHours, now: "2024-10-21 14:00", want: true},
+		{name: "outside the window in the rule's time zone", rule: workingHours, now: "2024-10-21 22:00"},
+		{name: "window end is exclusive", rule: workingHours, now: "2024-10-21 21:00"},
+		{name: "before the date range", rule: weekend, now: "2024-10-18 23:59"},
+		{name: "last day of the date range", rule: weekend, now: "2024-10-20 23:59", want: true},
+		{name: "after the date range", rule: weekend, now: "2024-10-21 00:00"},
+		{name: "no restrictions", rule: ScheduleRule{}, now: "2024-10-18 12:00", want: true},
+		{name: "unknown time zone", rule: ScheduleRule{TimeZone: "Mars/Olympus"}, now: "2024-10-18 12:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(at(tt.now)); got != tt.want {
+				t.Errorf("Matches(%s) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	RevisionActionCreate   = "create"
+	RevisionActionUpdate   = "update"
+	RevisionActionRollback = "rollback"
+)
+
+// UrlRevision is a snapshot of a url's destination and expiry settings
+// taken after each change, including everything that picks where a visitor
+// is sent
+type UrlRevision struct {
+	ID               primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	UrlId            primitive.ObjectID `json:"urlId" bson:"urlId"`
+	Version          int                `json:"version" bson:"version"`
+	Action           string             `json:"action" bson:"action"`
+	RolledBackTo     int                `json:"rolledBackTo,omitempty" bson:"rolledBackTo,omitempty"`
+	Changes          []string           `json:"changes" bson:"changes"` // fields that differ from the previous version
+	OriginalUrl      string             `json:"originalUrl" bson:"originalUrl"`
+	Expiration       int64              `json:"expiration" bson:"expiration"`
+	ExpirationType   string             `json:"expirationType" bson:"expirationType"`
+	ExpiresAt        *time.Time         `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+	RedirectType     int                `json:"redirectType" bson:"redirectType,omitempty"`
+	QueryPassthrough string             `json:"queryPassthrough" bson:"queryPassthrough,omitempty"`
+	Utm              *UtmParams         `json:"utm,omitempty" bson:"utm,omitempty"`
+	DeepLinks        *DeepLinks         `json:"deepLinks,omitempty" bson:"deepLinks,omitempty"`
+	GeoTargets       map[string]string  `json:"geoTargets,omitempty" bson:"geoTargets,omitempty"`
+	Variants         []Variant          `json:"variants,omitempty" bson:"variants,omitempty"`
+	StickyVariants   bool               `json:"stickyVariants" bson:"stickyVariants"`
+	ScheduleRules    []ScheduleRule     `json:"scheduleRules,omitempty" bson:"scheduleRules,omitempty"`
+	ChangedBy        primitive.ObjectID `json:"changedBy" bson:"changedBy"`
+	ChangedAt        time.Time          `json:"changedAt" bson:"changedAt"`
+}
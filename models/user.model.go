@@ -8,14 +8,17 @@ import (
 )
 
 type User struct {
-	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
-	FirstName string             `json:"firstName" binding:"required"`
-	LastName  string             `json:"lastName" binding:"required"`
-	Email     string             `json:"email" binding:"required"`
-	Password  []byte             `json:"-"`
-	Phone     string             `json:"phone" binding:"required"`
-	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
-	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+	ID          primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	FirstName   string             `json:"firstName" binding:"required"`
+	LastName    string             `json:"lastName" binding:"required"`
+	Email       string             `json:"email" binding:"required"`
+	Password    []byte             `json:"-"`
+	Phone       string             `json:"phone" binding:"required"`
+	IsAdmin     bool               `json:"isAdmin" bson:"isAdmin"`
+	DedupeUrls  bool               `json:"dedupeUrls" bson:"dedupeUrls"`                       // reuse existing links for repeated destinations
+	FallbackUrl string             `json:"fallbackUrl,omitempty" bson:"fallbackUrl,omitempty"` // for expired links and unknown codes on the user's domains
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt" bson:"updatedAt"`
 }
 
 func (user *User) SetPassword(password string) {
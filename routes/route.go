@@ -16,13 +16,39 @@ func Setup(app *gin.Engine) {
 	app.POST("/api/login", controllers.Login)
 	app.GET("/api/logout", controllers.Logout)
 
-	app.GET("/:shortURL", middleware.RateLimit, controllers.RedirectURL)
+	app.Match(controllers.VisitMethods, "/:shortURL", middleware.RateLimit, controllers.VisitURL)
+	app.GET("/preview/:shortURL", middleware.RateLimit, controllers.PreviewURL)
 
 	app.Use(middleware.IsAuthenticated)
 
+	app.GET("/api/account", controllers.GetAccount)
+	app.PUT("/api/account/settings", controllers.UpdateAccountSettings)
+
+	app.GET("/api/domains", controllers.GetAllDomains)
+	app.POST("/api/domains", controllers.CreateDomain)
+	app.POST("/api/domains/:id/verify", controllers.VerifyDomain)
+	app.DELETE("/api/domains/:id", controllers.DeleteDomain)
+
 	app.POST("/api/url", controllers.CreateUrl)
+	app.POST("/api/url/bulk", controllers.BulkCreateUrl)
+	app.POST("/api/url/import", controllers.ImportUrls)
+	app.GET("/api/url/import/:id", controllers.GetImport)
+	app.POST("/api/url/import/:id/run", controllers.RunImport)
+	app.GET("/api/url/trash", controllers.GetTrash)
 	app.GET("/api/url/:id", controllers.GetUrl)
 	app.PUT("/api/url/:id", controllers.UpdateUrl)
 	app.GET("/api/url", controllers.GetAllUrl)
 	app.DELETE("/api/url/:id", controllers.DeleteUrl)
+	app.PATCH("/api/url/:id/disable", controllers.DisableUrl)
+	app.PATCH("/api/url/:id/enable", controllers.EnableUrl)
+	app.POST("/api/url/:id/restore", controllers.RestoreUrl)
+	app.GET("/api/url/:id/history", controllers.GetUrlHistory)
+	app.POST("/api/url/:id/rollback/:version", controllers.RollbackUrl)
+	app.GET("/api/url/:id/qr", controllers.GetUrlQR)
+
+	admin := app.Group("/api/admin", middleware.IsAdmin)
+	admin.GET("/policies", controllers.GetAllPolicyRules)
+	admin.POST("/policies", controllers.CreatePolicyRule)
+	admin.PUT("/policies/:id", controllers.UpdatePolicyRule)
+	admin.DELETE("/policies/:id", controllers.DeletePolicyRule)
 }
\ No newline at end of file